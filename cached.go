@@ -0,0 +1,124 @@
+package env
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Signer = &cached{}
+
+// cached 缓存类型转换结果的查询器
+type cached struct {
+	Signer
+	values sync.Map // cacheKey => *cacheEntry
+}
+
+// cacheKey 缓存的方法与键名
+type cacheKey struct {
+	method string
+	key    string
+}
+
+// cacheEntry 缓存的解析结果，raw 与 extra 为解析时的原始值以及影响解析结果的设置
+type cacheEntry struct {
+	raw   string
+	extra string
+	value any
+}
+
+// Cached 返回一个缓存类型转换结果的查询器，适合在热点路径上反复读取同一个键。
+//
+// 每个方法与键名只缓存一个结果，原始值（或 Duration 使用的 DurationUnit）发生变化后
+// 会重新解析并替换旧的结果，因此无需手动失效，缓存的大小也不会随值的变化而增长；
+// 未覆盖的方法直接使用 s 的实现。
+func Cached(s Signer) Signer {
+	return &cached{Signer: s}
+}
+
+// load 返回解析后的值，第二个返回值表示数据存在且解析成功，
+// extra 为原始值以外会影响解析结果的设置
+func (c *cached) load(method, key, extra string, parse func(string) (any, error)) (any, bool) {
+	raw, ok := c.Signer.Lookup(key)
+	if !ok {
+		return nil, false
+	}
+	id := cacheKey{method: method, key: key}
+	if v, ok := c.values.Load(id); ok {
+		if entry := v.(*cacheEntry); entry.raw == raw && entry.extra == extra {
+			return entry.value, entry.value != nil
+		}
+	}
+	v, err := parse(raw)
+	if err != nil {
+		v = nil
+	}
+	c.values.Store(id, &cacheEntry{raw: raw, extra: extra, value: v})
+	return v, v != nil
+}
+
+func (c *cached) Int(key string, fallback ...int) int {
+	if v, ok := c.load("Int", key, "", func(raw string) (any, error) {
+		return strconv.Atoi(raw)
+	}); ok {
+		return v.(int)
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+func (c *cached) Float64(key string, fallback ...float64) float64 {
+	if v, ok := c.load("Float64", key, "", func(raw string) (any, error) {
+		return strconv.ParseFloat(raw, 64)
+	}); ok {
+		return v.(float64)
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+func (c *cached) Duration(key string, fallback ...time.Duration) time.Duration {
+	if v, ok := c.load("Duration", key, DurationUnit.String(), func(raw string) (any, error) {
+		return parseDuration(raw)
+	}); ok {
+		return v.(time.Duration)
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+func (c *cached) Bool(key string, fallback ...bool) bool {
+	if v, ok := c.load("Bool", key, "", func(raw string) (any, error) {
+		return parseBool(raw)
+	}); ok {
+		return v.(bool)
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return false
+}
+
+// List 返回缓存结果的副本，调用方可以自由修改
+func (c *cached) List(key string, fallback ...[]string) []string {
+	if v, ok := c.load("List", key, "", func(raw string) (any, error) {
+		parts := strings.Split(raw, ",")
+		for i, part := range parts {
+			parts[i] = strings.TrimSpace(part)
+		}
+		return parts, nil
+	}); ok {
+		return append([]string(nil), v.([]string)...)
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []string{}
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCached(t *testing.T) {
+	e := newTestEnviron(map[string]string{"PORT": "80", "HOSTS": "a, b", "TIMEOUT": "5"})
+	c := Cached(e).(*cached)
+
+	if got := c.Int("PORT"); got != 80 {
+		t.Errorf("Int(PORT) = %d, want 80", got)
+	}
+	c.List("HOSTS")
+	entry, _ := c.values.Load(cacheKey{method: "List", key: "HOSTS"})
+	c.List("HOSTS")
+	if again, _ := c.values.Load(cacheKey{method: "List", key: "HOSTS"}); again != entry {
+		t.Error("List(HOSTS) was parsed again without a change")
+	}
+
+	// 修改返回的切片不会影响缓存
+	hosts := c.List("HOSTS")
+	hosts[0] = "changed"
+	_ = append(hosts[:1], "appended")
+	if got := c.List("HOSTS"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("List(HOSTS) = %q after modifying a previous result, want [a b]", got)
+	}
+
+	e.Set("PORT", "8080")
+	if got := c.Int("PORT"); got != 8080 {
+		t.Errorf("Int(PORT) = %d after Set, want 8080", got)
+	}
+	e.Set("PORT", "http")
+	if got := c.Int("PORT", 1); got != 1 {
+		t.Errorf("Int(PORT) = %d for an invalid value, want fallback 1", got)
+	}
+
+	// 值多次变化后每个方法与键名仍然只有一个缓存
+	for n := 0; n < 10; n++ {
+		e.Set("PORT", fmt.Sprint(n))
+		c.Int("PORT")
+	}
+	var entries int
+	c.values.Range(func(_, _ any) bool {
+		entries++
+		return true
+	})
+	if entries != 2 {
+		t.Errorf("cache has %d entries, want 2", entries)
+	}
+}
+
+func TestCachedDurationUnit(t *testing.T) {
+	prev := DurationUnit
+	t.Cleanup(func() { DurationUnit = prev })
+	c := Cached(newTestEnviron(map[string]string{"TIMEOUT": "5"}))
+
+	if got := c.Duration("TIMEOUT"); got != 5*time.Second {
+		t.Errorf("Duration(TIMEOUT) = %v, want 5s", got)
+	}
+	DurationUnit = time.Millisecond
+	if got := c.Duration("TIMEOUT"); got != 5*time.Millisecond {
+		t.Errorf("Duration(TIMEOUT) = %v after changing DurationUnit, want 5ms", got)
+	}
+}
+
+func BenchmarkCachedInt(b *testing.B) {
+	e := newTestEnviron(map[string]string{"PORT": "8080"})
+	b.Run("Signer", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			e.Int("PORT")
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		c := Cached(e)
+		for n := 0; n < b.N; n++ {
+			c.Int("PORT")
+		}
+	})
+}
+
+func BenchmarkCachedList(b *testing.B) {
+	e := newTestEnviron(map[string]string{"HOSTS": "a.example.com, b.example.com, c.example.com"})
+	b.Run("Signer", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			e.List("HOSTS")
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		c := Cached(e)
+		for n := 0; n < b.N; n++ {
+			c.List("HOSTS")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package env
+
+import "context"
+
+// contextKey 在 context 中保存数据时使用的键类型，避免与其它包的键冲突
+type contextKey struct{ name string }
+
+// ContextWith 将全局数据中指定键的值保存到 ctx 中，便于沿调用链传递不可变的配置，
+// 数据不存在的键不会被保存
+func ContextWith(ctx context.Context, keys ...string) context.Context {
+	for _, key := range keys {
+		if value, ok := Default().Lookup(key); ok {
+			ctx = context.WithValue(ctx, contextKey{key}, value)
+		}
+	}
+	return ctx
+}
+
+// FromContext 读取通过 ContextWith 保存在 ctx 中的数据
+func FromContext(ctx context.Context, key string) (string, bool) {
+	value, ok := ctx.Value(contextKey{key}).(string)
+	return value, ok
+}
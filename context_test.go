@@ -0,0 +1,29 @@
+package env
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextWith(t *testing.T) {
+	e := useDefault(t, map[string]string{"REGION": "eu", "TENANT": "acme"})
+	ctx := ContextWith(context.Background(), "REGION", "TENANT", "MISSING")
+	// 保存后的修改不会影响 ctx 中的值
+	e.Set("REGION", "us")
+
+	if got, ok := FromContext(ctx, "REGION"); !ok || got != "eu" {
+		t.Errorf("FromContext(REGION) = %q, %v, want eu", got, ok)
+	}
+	if got, ok := FromContext(ctx, "TENANT"); !ok || got != "acme" {
+		t.Errorf("FromContext(TENANT) = %q, %v, want acme", got, ok)
+	}
+	if _, ok := FromContext(ctx, "MISSING"); ok {
+		t.Error("MISSING should not be stored in the context")
+	}
+	if _, ok := FromContext(context.WithValue(ctx, "REGION", "x"), "OTHER"); ok {
+		t.Error("OTHER should not be found")
+	}
+	if got, ok := FromContext(context.WithValue(ctx, "REGION", "x"), "REGION"); !ok || got != "eu" {
+		t.Errorf("FromContext(REGION) = %q, %v, plain string keys must not collide", got, ok)
+	}
+}
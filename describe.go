@@ -0,0 +1,62 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// FieldDoc 描述结构体中一个通过 `env` 标签绑定的字段
+type FieldDoc struct {
+	// Field 字段名称，嵌套结构体的字段使用 `.` 连接，如 `DB.Host`
+	Field string
+	// Key 字段对应的键名
+	Key string
+	// Default `default` 标签的值
+	Default string
+	// Required `required` 标签的值是否为真
+	Required bool
+	// Type 字段的类型
+	Type string
+}
+
+// Describe 按字段顺序返回结构体中所有 `env` 字段的描述，不会读取任何数据，
+// 可用于生成配置文档；structure 可以是结构体或结构体指针，其它类型返回 nil
+func Describe(structure any) []FieldDoc {
+	t := reflect.TypeOf(structure)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return describeStruct(t, "", map[reflect.Type]bool{})
+}
+
+// describeStruct 描述结构体的字段，seen 记录当前路径上的结构体类型，避免自引用的类型无限递归
+func describeStruct(t reflect.Type, prefix string, seen map[reflect.Type]bool) []FieldDoc {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	var docs []FieldDoc
+	for j := 0; j < t.NumField(); j++ {
+		field := t.Field(j)
+		name := prefix + field.Name
+		if key, exist := field.Tag.Lookup("env"); exist {
+			required, _ := strconv.ParseBool(field.Tag.Get("required"))
+			docs = append(docs, FieldDoc{
+				Field:    name,
+				Key:      key,
+				Default:  field.Tag.Get("default"),
+				Required: required,
+				Type:     field.Type.String(),
+			})
+		} else if field.Type.Kind() == reflect.Struct {
+			docs = append(docs, describeStruct(field.Type, name+".", seen)...)
+		} else if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			docs = append(docs, describeStruct(field.Type.Elem(), name+".", seen)...)
+		}
+	}
+	return docs
+}
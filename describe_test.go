@@ -0,0 +1,47 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	type DB struct {
+		Host string `env:"DB_HOST" required:"true"`
+		Port int    `env:"DB_PORT" default:"5432"`
+	}
+	type node struct {
+		Name string `env:"NODE_NAME"`
+		Next *node
+	}
+	type Config struct {
+		Name    string        `env:"APP_NAME" default:"demo"`
+		Timeout time.Duration `env:"TIMEOUT" default:"30s" required:"false"`
+		Hosts   []string      `env:"HOSTS"`
+		DB      DB
+		Replica *DB
+		Node    node
+		ignored string
+	}
+	want := []FieldDoc{
+		{Field: "Name", Key: "APP_NAME", Default: "demo", Type: "string"},
+		{Field: "Timeout", Key: "TIMEOUT", Default: "30s", Type: "time.Duration"},
+		{Field: "Hosts", Key: "HOSTS", Type: "[]string"},
+		{Field: "DB.Host", Key: "DB_HOST", Required: true, Type: "string"},
+		{Field: "DB.Port", Key: "DB_PORT", Default: "5432", Type: "int"},
+		{Field: "Replica.Host", Key: "DB_HOST", Required: true, Type: "string"},
+		{Field: "Replica.Port", Key: "DB_PORT", Default: "5432", Type: "int"},
+		{Field: "Node.Name", Key: "NODE_NAME", Type: "string"},
+	}
+	for _, structure := range []any{Config{}, &Config{}} {
+		if got := Describe(structure); !reflect.DeepEqual(got, want) {
+			t.Errorf("Describe(%T) = %+v, want %+v", structure, got, want)
+		}
+	}
+	for _, structure := range []any{nil, 1, new(int)} {
+		if got := Describe(structure); got != nil {
+			t.Errorf("Describe(%T) = %+v, want nil", structure, got)
+		}
+	}
+}
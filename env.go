@@ -1,10 +1,16 @@
 package env
 
 import (
+	"context"
 	"errors"
+	"io"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,52 +33,301 @@ import (
 //	cache.String("SCOPE")  // app:books:
 //
 // 这样就方便我们对环境变量简单分组分场景使用了。
+//
+// 查询时依次使用 `CACHE_BOOK_DATABASE`、`CACHE_DATABASE` 作为键名，
+// 二者都不存在（或值为空）时，才会使用调用时传入的默认值，比如
+// `cache.Int("DATABASE", 5)` 在只存在 `CACHE_DATABASE=1` 时返回 1 而不是 5。
 type Signer interface {
 	// Lookup 返回指定键的数据，只有存在指定的环境变量并且其值不为空时，
 	// 第二个返回值为 true，其它情况下，均返回 false，与方法 Exists 有所区别。
 	Lookup(key string) (string, bool)
+	// LookupScopedAndRoot 同时返回指定键在当前作用域（如 CACHE_BOOK_X 或 CACHE_X）
+	// 与根作用域（X）中的数据，便于排查作用域之间的冲突
+	LookupScopedAndRoot(key string) (scoped string, scopedOK bool, root string, rootOK bool)
 	// Exists 判断指定键的数据是否存在
 	// 只要存在键名就返回 true，不存在返回 false。
 	Exists(key string) bool
 	// String 返回指定键的数据的字符串形式，当数据不存在或值为空时返回默认值
 	String(key string, fallback ...string) string
-	// Bytes 返回指定键的数据的字节切片值，当数据不存在或值为空时返回默认值
+	// StringFunc 返回指定键的数据的字符串形式，当数据不存在或值为空时返回 provider 的结果
+	StringFunc(key string, provider func() string) string
+	// StringPtr 返回指向指定键的数据的指针，当数据不存在时返回 nil，值为空时返回指向空字符串的指针
+	StringPtr(key string) *string
+	// IntPtr 返回指向指定键的数据的整数值的指针，当数据不存在或无法解析时返回 nil
+	IntPtr(key string) *int
+	// BoolPtr 返回指向指定键的数据的布尔值的指针，当数据不存在或无法解析时返回 nil
+	BoolPtr(key string) *bool
+	// Bytes 返回指定键的数据的字节切片值（按照查询器的编码方式解码），
+	// 当数据不存在、值为空或解码失败时返回默认值
 	Bytes(key string, fallback ...[]byte) []byte
+	// Base64 返回指定键的数据按 base64（带或不带填充）解码后的值，
+	// 当数据不存在或值为空时返回默认值，解码失败时返回错误
+	Base64(key string, fallback ...[]byte) ([]byte, error)
+	// ByteSlice 将指定键的数据作为逗号分割的十进制字节列表（如 `10,20,30`）解析，
+	// 当数据不存在、值为空或任意元素不在 0-255 之间时返回默认值
+	ByteSlice(key string, fallback ...[]byte) []byte
 	// Int 返回指定键的数据的整数值，当数据不存在或值为空时返回默认值
 	Int(key string, fallback ...int) int
-	// Duration 返回指定键的数据的时长值，当数据不存在或值为空时返回默认值
+	// IntE 与 Int 相同，但数据存在却无法解析时返回错误（以及默认值）
+	IntE(key string, fallback ...int) (int, error)
+	// Int64 返回指定键的数据的 64 位整数值，当数据不存在、值为空或解析失败（包括溢出）时返回默认值
+	Int64(key string, fallback ...int64) int64
+	// Uint 返回指定键的数据的无符号整数值，当数据不存在、值为空或解析失败（包括溢出）时返回默认值
+	Uint(key string, fallback ...uint) uint
+	// Uint64 返回指定键的数据的 64 位无符号整数值，当数据不存在、值为空或解析失败（包括溢出）时返回默认值
+	Uint64(key string, fallback ...uint64) uint64
+	// IntRanges 将指定键的数据解析为整数列表，支持 `a-b` 形式的闭区间，如 `8080,9000-9005`，
+	// 格式有误、区间反向或展开后元素过多时返回错误
+	IntRanges(key string) ([]int, error)
+	// IntArray3 将指定键的数据解析为 3 个整数（如 `255,128,0`），
+	// 数据不存在、元素数量不符或任意元素无效时返回错误
+	IntArray3(key string) ([3]int, error)
+	// Float64 返回指定键的数据的浮点数值（支持科学计数法），当数据不存在或值为空时返回默认值
+	Float64(key string, fallback ...float64) float64
+	// Float64E 与 Float64 相同，但数据存在却无法解析时返回错误（以及默认值）
+	Float64E(key string, fallback ...float64) (float64, error)
+	// Float32 返回指定键的数据的单精度浮点数值，当数据不存在、值为空或解析失败时返回默认值
+	Float32(key string, fallback ...float32) float32
+	// Float64Locale 与 Float64 相同，但允许值中包含千位分隔符 `,`，如 `1,000.50`
+	Float64Locale(key string, fallback ...float64) float64
+	// SampleRate 返回指定键的数据的采样率（支持 `0.1` 与 `10%`，结果限制在 [0, 1]），
+	// 当数据不存在或值为空时返回默认值
+	SampleRate(key string, fallback ...float64) float64
+	// Duration 返回指定键的数据的时长值，不带单位的整数使用 DurationUnit（默认为秒）作为单位，
+	// 当数据不存在或值为空时返回默认值
 	Duration(key string, fallback ...time.Duration) time.Duration
-	// Bool 返回指定键的数据的布尔值，当数据不存在或值为空时返回默认值
+	// DurationE 与 Duration 相同，但数据存在却无法解析时返回错误（以及默认值）
+	DurationE(key string, fallback ...time.Duration) (time.Duration, error)
+	// DurationSum 返回指定键的数据中以英文逗号分割的多个时长之和（如 `30s,500ms`），
+	// 当数据不存在或任意元素无效时返回 0
+	DurationSum(key string) time.Duration
+	// Schedule 将指定键的数据解析为时间表，如 `1s,2s x3,5s`（` xN` 表示重复 N 次），
+	// 当数据不存在、值为空或格式有误时返回空切片
+	Schedule(key string) []time.Duration
+	// Cron 返回指定键的 cron 表达式，仅支持 5 个或 6 个字段，数据不存在或格式有误时返回错误
+	Cron(key string) (string, error)
+	// Bandwidth 将指定键的数据（如 `10MB/s`、`500KiB/s`，不带单位时视为字节每秒）换算为每秒的字节数，
+	// 数据不存在时返回 0，格式有误时返回错误
+	Bandwidth(key string) (bytesPerSec int64, err error)
+	// TimeOfDay 将指定键的数据作为 `HH:MM` 格式的时刻解析，数据不存在或格式有误时返回错误
+	TimeOfDay(key string) (hour, minute int, err error)
+	// Rate 将指定键的数据（如 `100/s`、`60/m`、`3600/h`，不带单位时视为每秒）换算为每秒的次数，
+	// 数据不存在时返回 0，格式有误时返回错误
+	Rate(key string) (perSecond float64, err error)
+	// Until 将指定键的数据作为 Unix 时间戳（秒），返回当前时间距该时间的时长（已过去时为负值），
+	// 当数据不存在或值为空时返回默认值
+	Until(key string, fallback ...time.Duration) time.Duration
+	// Time 返回指定键的数据按 RFC3339 格式解析的时间，当数据不存在、值为空或解析失败时返回默认值（或零值）
+	Time(key string, fallback ...time.Time) time.Time
+	// TimeLayout 返回指定键的数据按 layout 格式解析的时间，当数据不存在、值为空或解析失败时返回默认值（或零值）
+	TimeLayout(key, layout string, fallback ...time.Time) time.Time
+	// Bool 返回指定键的数据的布尔值（支持 strconv.ParseBool 的写法以及 yes/no、on/off、y/n），
+	// 当数据不存在或值为空时返回默认值
 	Bool(key string, fallback ...bool) bool
+	// BoolE 与 Bool 相同，但数据存在却无法解析时返回错误（以及默认值）
+	BoolE(key string, fallback ...bool) (bool, error)
+	// BoolList 返回指定键的数据的布尔值列表（使用英文逗号分割），
+	// 当数据不存在、值为空或任意元素无效时返回默认值
+	BoolList(key string, fallback ...[]bool) []bool
+	// BoolOrInt 返回指定键的开关或等级值，如 `VERBOSE=true` 返回 (true, 0)，
+	// `VERBOSE=3` 返回 (true, 3)，数据不存在或为假值时返回 (false, 0)
+	BoolOrInt(key string) (enabled bool, level int)
+	// FileMode 返回指定键的数据的文件权限值（八进制），当数据不存在或值为空时返回默认值
+	FileMode(key string, fallback ...os.FileMode) os.FileMode
 	// List 返回指定键的数据的字符串列表（使用英文逗号分割），当数据不存在或值为空时返回默认值
 	List(key string, fallback ...[]string) []string
+	// ListSep 返回指定键的数据的字符串列表（使用 sep 分割，如 PATH 风格的 `:`），当数据不存在或值为空时返回默认值
+	ListSep(key, sep string, fallback ...[]string) []string
+	// ListMerged 合并类目键与缺省键的字符串列表（类目键在前，去除重复元素），
+	// 如同时存在 CACHE_BOOK_HOSTS 与 CACHE_HOSTS 时返回两者的并集
+	ListMerged(key string) []string
+	// ListAuto 返回指定键的数据的字符串列表，分隔符按 `,`、`;`、换行符、空白字符的顺序自动识别，
+	// 当数据不存在或值为空时返回空切片
+	ListAuto(key string) []string
+	// ListN 与 List 相同，但元素数量超过 max 时视为无效值并返回默认值（而不是截断）
+	ListN(key string, max int, fallback ...[]string) []string
+	// ListEscaped 与 List 相同，但允许使用 `\,` 表示值中的逗号，使用 `\\` 表示反斜杠
+	ListEscaped(key string, fallback ...[]string) []string
+	// ListCSV 将指定键的数据作为一条 CSV 记录解析，支持使用双引号包裹含有逗号的元素，
+	// 当数据不存在、值为空或解析失败时返回默认值
+	ListCSV(key string, fallback ...[]string) []string
+	// URLList 返回指定键的数据的 URL 列表（使用英文逗号分割，每个元素必须是绝对地址），
+	// 当数据不存在、值为空或任意元素解析失败时返回默认值
+	URLList(key string, fallback ...[]*url.URL) []*url.URL
+	// IPList 返回指定键的数据的 IP 地址列表（使用英文逗号分割），
+	// 当数据不存在、值为空或任意元素解析失败时返回默认值
+	IPList(key string, fallback ...[]net.IP) []net.IP
+	// MAC 返回指定键的数据的硬件地址（如 `00:1a:2b:3c:4d:5e`），当数据不存在或解析失败时返回默认值
+	MAC(key string, fallback ...net.HardwareAddr) net.HardwareAddr
+	// WeightedMap 将指定键的数据解析为带权重的映射（如 `a:3,b:1`，省略权重时为 1），
+	// 当数据不存在、值为空或权重无效时返回默认值
+	WeightedMap(key string, fallback ...map[string]int) map[string]int
+	// LookupEnumRequired 返回指定键的枚举值，当数据不存在、值为空或值不在 allowed 中时返回错误
+	LookupEnumRequired(key string, allowed []string) (string, error)
+	// Render 将指定键的数据作为 text/template 模板并使用 data 渲染，
+	// 当数据不存在、值为空或模板有误时返回错误
+	Render(key string, data any) (string, error)
 	// Map 将具体相同前缀的键的数据聚合起来返回
 	Map(prefix string) map[string]string
+	// MapStrict 与 Map 相同，但去除前缀后的键名只有大小写或 `.` 与 `_` 不同（互为别名）时返回错误
+	MapStrict(prefix string) (map[string]string, error)
 	// Where 返回通过自定义函数过滤的数据
 	Where(filter func(name, value string) bool) map[string]string
-	// Fill 使用环境变量填充结构体
+	// WhereQualified 与 Where 相同，但过滤器同时接收完整键名（如 `CACHE_BOOK_X`）
+	// 与签名查询器中的短键名（如 `X`），返回结果使用短键名
+	WhereQualified(filter func(qualifiedKey, shortKey, value string) bool) map[string]string
+	// Typed 返回所有数据，值会被推断并转换为 int、bool、float64、time.Duration 或 string
+	Typed() map[string]any
+	// ExportEnviron 以 `KEY=value` 的形式导出数据，签名查询器导出的键名不包含前缀，
+	// 适合作为子进程的环境变量（exec.Cmd.Env）使用
+	ExportEnviron() []string
+	// Fill 使用环境变量填充结构体，字段通过 `env` 标签指定键名，值为空时依次使用
+	// `default-{APP_ENV}`（如 `default-dev`）与 `default` 标签的值作为默认值；
+	// 切片字段的值使用英文逗号分割，映射字段的值使用 `k=v;k2=v2` 格式
 	Fill(structure any) error
+	// FillAll 与 Fill 相同，但不会在第一个错误处停止，返回的错误包含所有转换失败的字段及其键名
+	FillAll(structure any) error
+	// MustFill 使用环境变量填充结构体，参数无效或字段转换失败时 panic
+	MustFill(structure any)
+	// JSON 将指定键的数据作为 JSON 文档解码到 out 中，数据不存在或解码失败时返回错误
+	JSON(key string, out any) error
+	// Bind 将指定键的数据转换为 target（非 nil 指针）所指向的类型并赋值，
+	// 当数据不存在或值为空时使用默认值，没有默认值时保持 target 不变
+	Bind(key string, target any, fallback ...any) error
+	// MustString 返回指定键的数据，数据不存在或值为空时 panic
+	MustString(key string) string
+	// MustInt 返回指定键的数据的整数值，数据不存在、值为空或无法解析时 panic
+	MustInt(key string) int
+	// MustBool 返回指定键的数据的布尔值，数据不存在、值为空或无法解析时 panic
+	MustBool(key string) bool
+	// MustDuration 返回指定键的数据的时长，数据不存在、值为空或无法解析时 panic
+	MustDuration(key string) time.Duration
+	// Save 保存数据，已存在的键会被覆盖，签名查询器会使用 prefix_category_key
+	// 形式的完整键名将数据写入根作用域
+	Save(data map[string]string)
+	// WithEncoding 返回一个相同作用域的查询器，其 Bytes 方法会按照 enc 解码数据，
+	// 适合整个作用域都使用同一种编码存储二进制数据的场景
+	WithEncoding(enc Encoding) Signer
 }
 
 type Environ interface {
 	Signer
-	// Load 加载定义环境变量的文件
+	// Load 加载定义环境变量的文件，文件中的值会覆盖已存在的键
 	Load(filenames ...string) error
+	// LoadNoOverride 加载定义环境变量的文件，只补充尚不存在的键
+	LoadNoOverride(filenames ...string) error
+	// LoadReader 从 r 中加载 .env 格式的数据，值会覆盖已存在的键
+	LoadReader(r io.Reader) error
+	// LoadMap 加载已有的键值，值会覆盖已存在的键
+	LoadMap(data map[string]string)
+	// Dump 按写入顺序将所有数据序列化为 .env 格式
+	Dump() (string, error)
+	// WriteFile 将 Dump 的结果原子地写入文件
+	WriteFile(path string) error
+	// LoadOS 加载系统的环境变量，并保留一份快照
+	LoadOS()
+	// Set 设置单个键的值，已存在的键会被覆盖
+	Set(key, value string)
+	// Unset 移除单个键
+	Unset(key string)
+	// ResetKeyToOS 将指定键恢复为 LoadOS 时系统环境变量中的值，当时不存在则移除该键
+	ResetKeyToOS(key string)
+	// Reload 重新加载单个文件，返回因此新增或值发生变化的键值
+	Reload(filename string) (changed map[string]string, err error)
+	// LoadJSON 加载 JSON 格式的配置文件，嵌套的键会被展开为下划线连接的大写键名
+	LoadJSON(filename string) error
+	// LoadYAML 加载 YAML 格式的配置文件，嵌套的键会被展开为下划线连接的大写键名
+	LoadYAML(filename string) error
+	// RegisterLazyFile 注册延迟加载的环境变量文件，第一次查询不到数据时才会读取
+	RegisterLazyFile(filename string)
+	// With 临时覆盖数据并执行 fn，fn 返回后恢复原有数据
+	With(overrides map[string]string, fn func())
+	// RegisterScheme 注册外部数据解析器，读取 `{scheme}://{ref}` 形式的值时使用 resolver 解析
+	RegisterScheme(scheme string, resolver func(ref string) (string, error))
+	// SetBlocklist 设置禁止读取的键，这些键即使存在也会被视为不存在
+	SetBlocklist(keys ...string)
+	// SetExpand 设置是否在读取时展开值中的 `${KEY}` 引用，签名查询器会优先在
+	// 自身作用域内解析引用，找不到时再从根作用域解析
+	SetExpand(enabled bool)
+	// SetNormalizeKeys 设置是否在读取时规范化键名，开启后 `cache.book.database`
+	// 可以读取到 CACHE_BOOK_DATABASE 的数据
+	SetNormalizeKeys(enabled bool)
+	// SetReadAuditor 设置读取审计函数，每次查询数据时都会被调用
+	SetReadAuditor(auditor func(key string, found bool))
+	// CheckReferences 返回所有值中无法解析的 `${KEY}` 引用
+	CheckReferences() []string
+	// Pairs 按写入顺序返回所有键值的副本
+	Pairs() []Pair
+	// ChangesSince 与之前保存的快照（如 All 的返回值）对比，返回新增、移除和值发生变化的键
+	ChangesSince(snapshot map[string]string) (added, removed, changed []string)
+	// BindAtomicString 返回一个与 key 绑定的原子值，key 的数据发生变化时会自动更新
+	BindAtomicString(key string) *atomic.Pointer[string]
+	// BindAtomicInt 返回一个与 key 绑定的整型原子值，key 的数据发生变化时会自动更新
+	BindAtomicInt(key string) *atomic.Int64
+	// BindAtomicBool 返回一个与 key 绑定的布尔原子值，key 的数据发生变化时会自动更新
+	BindAtomicBool(key string) *atomic.Bool
+	// Unbind 解除通过 BindAtomicString 等方法返回的原子值的绑定，绑定在解除之前会一直保留
+	Unbind(holder any)
 	// Signed 返回复合一个规则的签名查询器
 	Signed(prefix, category string) Signer
+	// Watch 定期检查加载过的文件，内容发生变化时重新加载，确实有数据变化或加载失败时调用 onReload，
+	// 不会阻塞，取消 ctx 即可停止
+	Watch(ctx context.Context, onReload func(error)) error
+	// WatchChanges 与 Watch 相同，但 onChange 会收到新增或值发生变化的键值，文件内容变化但值未变时不会调用
+	WatchChanges(ctx context.Context, onChange func(changed map[string]string, err error)) error
+	// ExportToOS 将所有数据写入进程的环境变量，overwrite 为 false 时跳过系统中已存在的环境变量
+	ExportToOS(overwrite bool) error
 	// Clean 清理缓存的所有数据
 	Clean()
 }
 
 var (
-	// 全局缓存的环境变量
-	env = New().(*environ)
+	// 全局缓存的环境变量，包级函数通过 Default 读取，通过 SetDefault 替换
+	env   = New()
+	envMu sync.RWMutex
 	// 环境变量文件 `.env` 所处的目录
 	// 一般位于程序的工作目录
 	root string
+	// 是否通过 InitEnvOnly 初始化，此时重新加载也不会读取任何文件
+	envOnly bool
+	// 串行化 Init 与 ReloadAll，避免并发加载时相互覆盖
+	initMu sync.Mutex
+	// 通过 AddPostLoadHook 注册的加载后处理函数
+	postLoadHooks []func(e Environ)
+	hooksMu       sync.Mutex
+	// 通过 SignedDefault 注册的默认签名规则
+	scopePrefix   string
+	scopeCategory string
+	scopeMu       sync.RWMutex
 )
 
+// Default 返回包级函数使用的环境变量实例
+func Default() Environ {
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return env
+}
+
+// SetDefault 替换包级函数使用的环境变量实例，传入 nil 时恢复为一个新的空实例，
+// 测试时可以先通过 Default 保存原实例，结束后再通过 SetDefault 还原。
+//
+// 替换后 Init、InitEnvOnly 会加载到新实例中；ReloadAll 与 Reload 需要原子地替换数据，
+// 只支持通过 New 创建的实例，替换为其它 Environ 实现后调用会返回错误。
+func SetDefault(e Environ) {
+	if e == nil {
+		e = New()
+	}
+	envMu.Lock()
+	defer envMu.Unlock()
+	env = e
+}
+
 // Init 加载运行目录下的 .env 文件
+//
+// 加载顺序为系统环境变量、.env.defaults、.env、.env.local、.env.{APP_ENV}、
+// .env.{APP_ENV}.local，其中 .env.defaults 只提供尚未设置的键的默认值，
+// 其余文件中的值会覆盖之前加载的值（包括系统环境变量）。如需让系统环境变量优先，
+// 可以在 LoadOS 之后使用 LoadNoOverride 加载文件。
 func Init(root ...string) error {
 	var dir string
 	if len(root) > 0 {
@@ -91,10 +346,14 @@ func InitWithDir(dir string) (err error) {
 		return
 	}
 
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	e := Default()
 	defer func() {
 		if err != nil {
 			root = ""
-			env.Clean()
+			e.Clean()
 		} else {
 			root = dir
 		}
@@ -102,59 +361,404 @@ func InitWithDir(dir string) (err error) {
 
 	// 重置缓存的环境变量
 	root = ""
-	env.Clean()
+	envOnly = false
+	e.Clean()
 
 	// 加载系统的环境变量
-	result := make(map[string]string)
-	for _, value := range os.Environ() {
-		parts := strings.SplitN(value, "=", 2)
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
-		result[key] = val
+	e.LoadOS()
+
+	// 加载 .env 系列文件
+	if err = loadFiles(e, dir); err != nil {
+		return
 	}
-	env.Save(result)
 
-	// 加载 .env 和 .env.local 文件
-	err = loadEnv(dir, "")
+	runPostLoadHooks(e)
+	return nil
+}
+
+// InitEnvOnly 只加载系统的环境变量，不查找和加载任何 .env 系列文件，
+// 适合在 CI 等需要忽略目录中残留文件的场景下使用，root 会被设置为当前工作目录
+func InitEnvOnly() error {
+	dir, err := os.Getwd()
 	if err != nil {
 		return err
 	}
 
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	e := Default()
+	e.Clean()
+	e.LoadOS()
+	runPostLoadHooks(e)
+	root = dir
+	envOnly = true
+	return nil
+}
+
+// AddPostLoadHook 注册加载后处理函数，在 Init、InitWithDir、InitEnvOnly、ReloadAll、Open
+// 以及包级的 Load 系列函数加载完成后按注册顺序调用，可用于集中规范化数据，比如
+// 去除键名前缀或根据已有的键生成新的键；ReloadAll 会在替换全局数据之前对新数据调用。
+func AddPostLoadHook(fn func(e Environ)) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	postLoadHooks = append(postLoadHooks, fn)
+}
+
+// runPostLoadHooks 依次调用注册的加载后处理函数
+func runPostLoadHooks(e Environ) {
+	hooksMu.Lock()
+	hooks := append([]func(e Environ){}, postLoadHooks...)
+	hooksMu.Unlock()
+	for _, fn := range hooks {
+		fn(e)
+	}
+}
+
+// ErrNotInitialized 尚未通过 Init、InitWithDir 或 InitEnvOnly 成功加载时，重新加载全局数据会返回该错误
+var ErrNotInitialized = errors.New("env: not initialized")
+
+// ReloadAll 按照 Init 的规则重新加载系统环境变量与 .env 系列文件（通过 InitEnvOnly
+// 初始化时只加载系统环境变量），全部加载成功后才一次性替换全局数据，读取方不会看到
+// 加载到一半的状态；返回新增、值发生变化以及被移除（值为空字符串）的键值，加载失败时保留原有数据。
+//
+// 全局实例必须是通过 New 创建的，通过 SetDefault 替换为其它 Environ 实现后会返回错误。
+func ReloadAll() (changed map[string]string, err error) {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if root == "" {
+		return nil, ErrNotInitialized
+	}
+	current, ok := Default().(*environ)
+	if !ok {
+		return nil, errors.New("env: ReloadAll requires an instance created by New")
+	}
+	fresh := New().(*environ)
+	fresh.LoadOS()
+	if !envOnly {
+		if err = loadFiles(fresh, root); err != nil {
+			return nil, err
+		}
+	}
+	runPostLoadHooks(fresh)
+	return current.swap(fresh), nil
+}
+
+// Reload 使用最近一次 Init、InitWithDir 或 InitEnvOnly 的参数重新加载全局数据，
+// 加载顺序与 Init 相同，Path 返回的目录保持不变；尚未初始化时返回 ErrNotInitialized。
+// 与 ReloadAll 相同，全部加载成功后才会替换原有数据，同样只支持通过 New 创建的全局实例，
+// 需要变化的键值时请使用 ReloadAll；只重新加载单个文件请使用 ReloadFile。
+func Reload() error {
+	_, err := ReloadAll()
+	return err
+}
+
+// Options 使用 Open 创建环境变量实例时的选项
+type Options struct {
+	// Dir 加载 .env 系列文件的目录（规则与 Init 一致），为空时不加载
+	Dir string
+	// Files 在 .env 系列文件之后额外加载的文件
+	Files []string
+	// IgnoreOS 为 true 时不加载系统的环境变量
+	IgnoreOS bool
+}
+
+// Open 按照选项创建并加载一个独立的环境变量实例，不会影响全局实例
+func Open(opts Options) (Environ, error) {
+	e := New()
+	if !opts.IgnoreOS {
+		e.LoadOS()
+	}
+	if opts.Dir != "" {
+		dir, err := filepath.Abs(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if err = loadFiles(e, dir); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Files) > 0 {
+		if err := e.Load(opts.Files...); err != nil {
+			return nil, err
+		}
+	}
+	runPostLoadHooks(e)
+	return e, nil
+}
+
+// loadFiles 加载 dir 目录下的 .env 系列文件
+func loadFiles(e Environ, dir string) error {
+	// 加载 .env.defaults 文件，其中的值只用于补充尚不存在的键
+	if err := loadDefaults(e, filepath.Join(dir, ".env.defaults")); err != nil {
+		return err
+	}
+
+	// 加载 .env 和 .env.local 文件
+	if err := loadEnv(e, envFiles(dir, "")...); err != nil {
+		return err
+	}
+
 	// 加载与运行环境相关的环境变量
-	appEnv := String("APP_ENV", "prod")
+	appEnv := e.String("APP_ENV", "prod")
 	if len(appEnv) > 0 {
 		// 加载 .env.{APP_ENV} 和 .env.{APP_ENV}.local 文件
-		err = loadEnv(dir, "."+strings.ToLower(appEnv))
-		if err != nil {
+		if err := loadEnv(e, envFiles(dir, appEnv)...); err != nil {
 			return err
 		}
 	}
 
-	return
+	return nil
 }
 
-func loadEnv(dir, env string) error {
-	filename := filepath.Join(dir, ".env"+env)
-	if err := Load(filename); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return err
+// loadEnv 依次加载文件，忽略不存在的文件
+func loadEnv(e Environ, filenames ...string) error {
+	for _, filename := range filenames {
+		if err := e.Load(filename); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
 		}
 	}
-	if err := Load(filename + ".local"); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return err
-		}
+	return nil
+}
+
+// loadDefaults 加载默认值文件，只保存尚不存在的键，文件不存在时忽略
+func loadDefaults(e Environ, filename string) error {
+	if err := e.LoadNoOverride(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
 	}
 	return nil
 }
 
+// EnvFilesFor 返回 Init 在 dir 目录下按顺序尝试加载的文件列表，依次为
+// .env.defaults、.env、.env.local、.env.{appEnv}、.env.{appEnv}.local，
+// appEnv 为空时不包含最后两个
+func EnvFilesFor(dir, appEnv string) []string {
+	files := append([]string{filepath.Join(dir, ".env.defaults")}, envFiles(dir, "")...)
+	if appEnv != "" {
+		files = append(files, envFiles(dir, appEnv)...)
+	}
+	return files
+}
+
+// envFiles 返回 .env{.appEnv} 与 .env{.appEnv}.local 两个文件
+func envFiles(dir, appEnv string) []string {
+	filename := filepath.Join(dir, ".env")
+	if appEnv != "" {
+		filename += "." + strings.ToLower(appEnv)
+	}
+	return []string{filename, filename + ".local"}
+}
+
 // Load 加载指定的环境变量文件
 func Load(filenames ...string) error {
-	return env.Load(filenames...)
+	e := Default()
+	if err := e.Load(filenames...); err != nil {
+		return err
+	}
+	runPostLoadHooks(e)
+	return nil
+}
+
+// LoadNoOverride 加载指定的环境变量文件，只补充全局数据中尚不存在的键
+func LoadNoOverride(filenames ...string) error {
+	e := Default()
+	if err := e.LoadNoOverride(filenames...); err != nil {
+		return err
+	}
+	runPostLoadHooks(e)
+	return nil
+}
+
+// LoadReader 从 r 中加载 .env 格式的数据
+func LoadReader(r io.Reader) error {
+	e := Default()
+	if err := e.LoadReader(r); err != nil {
+		return err
+	}
+	runPostLoadHooks(e)
+	return nil
+}
+
+// LoadMap 将已有的键值加载到全局数据中，已存在的键会被覆盖
+func LoadMap(data map[string]string) {
+	e := Default()
+	e.LoadMap(data)
+	runPostLoadHooks(e)
+}
+
+// Dump 将全局数据序列化为 .env 格式
+func Dump() (string, error) {
+	return Default().Dump()
+}
+
+// WriteFile 将全局数据原子地写入 .env 格式的文件
+func WriteFile(path string) error {
+	return Default().WriteFile(path)
+}
+
+// ReloadFile 为全局实例重新加载单个文件，返回新增或值发生变化的键值；
+// 按照 Init 的规则重新加载全部文件请使用 Reload 或 ReloadAll。
+func ReloadFile(filename string) (map[string]string, error) {
+	return Default().Reload(filename)
+}
+
+// Set 设置全局数据中单个键的值，已存在的键会被覆盖
+func Set(name, value string) {
+	Default().Set(name, value)
+}
+
+// Unset 移除全局数据中的单个键
+func Unset(name string) {
+	Default().Unset(name)
+}
+
+// ResetKeyToOS 将全局数据中的指定键恢复为初始化时系统环境变量中的值
+func ResetKeyToOS(name string) {
+	Default().ResetKeyToOS(name)
+}
+
+// LoadJSON 加载指定的 JSON 配置文件
+func LoadJSON(filename string) error {
+	e := Default()
+	if err := e.LoadJSON(filename); err != nil {
+		return err
+	}
+	runPostLoadHooks(e)
+	return nil
+}
+
+// LoadYAML 加载指定的 YAML 配置文件
+func LoadYAML(filename string) error {
+	e := Default()
+	if err := e.LoadYAML(filename); err != nil {
+		return err
+	}
+	runPostLoadHooks(e)
+	return nil
+}
+
+// RegisterLazyFile 为全局实例注册延迟加载的环境变量文件
+func RegisterLazyFile(filename string) {
+	Default().RegisterLazyFile(filename)
+}
+
+// With 临时覆盖全局数据并执行 fn，fn 返回后恢复原有数据
+func With(overrides map[string]string, fn func()) {
+	Default().With(overrides, fn)
+}
+
+// RegisterScheme 为全局实例注册外部数据解析器，比如
+//
+//	env.RegisterScheme("secret", func(ref string) (string, error) {
+//		return vault.Read(ref)
+//	})
+//	env.String("DB_PASSWORD") // DB_PASSWORD=secret://db/password
+func RegisterScheme(scheme string, resolver func(ref string) (string, error)) {
+	Default().RegisterScheme(scheme, resolver)
+}
+
+// SetBlocklist 设置全局实例禁止读取的键
+func SetBlocklist(keys ...string) {
+	Default().SetBlocklist(keys...)
+}
+
+// SetExpand 设置全局实例是否在读取时展开值中的引用
+func SetExpand(enabled bool) {
+	Default().SetExpand(enabled)
+}
+
+// SetNormalizeKeys 设置全局实例是否在读取时规范化键名
+func SetNormalizeKeys(enabled bool) {
+	Default().SetNormalizeKeys(enabled)
+}
+
+// SetReadAuditor 设置全局实例的读取审计函数
+func SetReadAuditor(auditor func(key string, found bool)) {
+	Default().SetReadAuditor(auditor)
+}
+
+// CheckReferences 返回全局数据中无法解析的 `${KEY}` 引用
+func CheckReferences() []string {
+	return Default().CheckReferences()
+}
+
+// Pairs 按写入顺序返回全局数据的副本
+func Pairs() []Pair {
+	return Default().Pairs()
+}
+
+// ChangesSince 返回全局数据相对于快照新增、移除和值发生变化的键
+func ChangesSince(snapshot map[string]string) (added, removed, changed []string) {
+	return Default().ChangesSince(snapshot)
+}
+
+// BindAtomicString 返回一个与全局数据 name 绑定的原子值
+func BindAtomicString(name string) *atomic.Pointer[string] {
+	return Default().BindAtomicString(name)
+}
+
+// BindAtomicInt 返回一个与全局数据 name 绑定的整型原子值
+func BindAtomicInt(name string) *atomic.Int64 {
+	return Default().BindAtomicInt(name)
+}
+
+// BindAtomicBool 返回一个与全局数据 name 绑定的布尔原子值
+func BindAtomicBool(name string) *atomic.Bool {
+	return Default().BindAtomicBool(name)
+}
+
+// Unbind 解除原子值与全局数据的绑定
+func Unbind(holder any) {
+	Default().Unbind(holder)
 }
 
 func Signed(prefix, category string) Signer {
-	return env.Signed(prefix, category)
+	return Default().Signed(prefix, category)
+}
+
+// SignedDefault 注册默认的签名规则，之后可以通过 Scoped 以及
+// ScopedString、ScopedInt 等函数直接读取该作用域内的数据
+func SignedDefault(prefix, category string) Signer {
+	scopeMu.Lock()
+	scopePrefix, scopeCategory = prefix, category
+	scopeMu.Unlock()
+	return Scoped()
+}
+
+// Scoped 返回通过 SignedDefault 注册的默认签名查询器，
+// 未注册时返回的查询器等同于全局实例
+func Scoped() Signer {
+	scopeMu.RLock()
+	prefix, category := scopePrefix, scopeCategory
+	scopeMu.RUnlock()
+	return Default().Signed(prefix, category)
+}
+
+// ScopedString 通过默认签名查询器取字符串值
+func ScopedString(name string, value ...string) string {
+	return Scoped().String(name, value...)
+}
+
+// ScopedInt 通过默认签名查询器取整型值
+func ScopedInt(name string, value ...int) int {
+	return Scoped().Int(name, value...)
+}
+
+// ScopedBool 通过默认签名查询器取布尔值
+func ScopedBool(name string, value ...bool) bool {
+	return Scoped().Bool(name, value...)
+}
+
+// ScopedDuration 通过默认签名查询器取时长值
+func ScopedDuration(name string, value ...time.Duration) time.Duration {
+	return Scoped().Duration(name, value...)
+}
+
+// ScopedList 通过默认签名查询器取字符串列表
+func ScopedList(name string, fallback ...[]string) []string {
+	return Scoped().List(name, fallback...)
 }
 
 // Path 基于初始化目录获取目录
@@ -176,58 +780,343 @@ func IsEnv(env string) bool {
 
 // Lookup 查看配置
 func Lookup(name string) (string, bool) {
-	return env.Lookup(name)
+	return Default().Lookup(name)
 }
 
 // Exists 配置是否存在
 func Exists(name string) bool {
-	return env.Exists(name)
+	return Default().Exists(name)
 }
 
 // String 取字符串值
 func String(name string, value ...string) string {
-	return env.String(name, value...)
+	return Default().String(name, value...)
+}
+
+// StringFunc 取字符串值，不存在时调用 provider
+func StringFunc(name string, provider func() string) string {
+	return Default().StringFunc(name, provider)
+}
+
+// StringPtr 取字符串值的指针，不存在时返回 nil
+func StringPtr(name string) *string {
+	return Default().StringPtr(name)
+}
+
+// IntPtr 取整数值的指针，不存在或无法解析时返回 nil
+func IntPtr(name string) *int {
+	return Default().IntPtr(name)
+}
+
+// BoolPtr 取布尔值的指针，不存在或无法解析时返回 nil
+func BoolPtr(name string) *bool {
+	return Default().BoolPtr(name)
 }
 
 // Bytes 取二进制值
 func Bytes(name string, value ...[]byte) []byte {
-	return env.Bytes(name, value...)
+	return Default().Bytes(name, value...)
+}
+
+// Base64 取 base64 解码后的值
+func Base64(name string, fallback ...[]byte) ([]byte, error) {
+	return Default().Base64(name, fallback...)
+}
+
+// ByteSlice 取逗号分割的十进制字节列表
+func ByteSlice(name string, value ...[]byte) []byte {
+	return Default().ByteSlice(name, value...)
 }
 
 // Int 取整型值
 func Int(name string, value ...int) int {
-	return env.Int(name, value...)
+	return Default().Int(name, value...)
+}
+
+// IntE 与 Int 相同，但值无法解析时返回错误
+func IntE(name string, value ...int) (int, error) {
+	return Default().IntE(name, value...)
+}
+
+// Int64 取 64 位整型值
+func Int64(name string, value ...int64) int64 {
+	return Default().Int64(name, value...)
+}
+
+// Uint 取无符号整型值
+func Uint(name string, value ...uint) uint {
+	return Default().Uint(name, value...)
+}
+
+// Uint64 取 64 位无符号整型值
+func Uint64(name string, value ...uint64) uint64 {
+	return Default().Uint64(name, value...)
+}
+
+// IntRanges 取展开后的整数区间列表
+func IntRanges(name string) ([]int, error) {
+	return Default().IntRanges(name)
+}
+
+// IntArray3 取固定 3 个元素的整数数组
+func IntArray3(name string) ([3]int, error) {
+	return Default().IntArray3(name)
+}
+
+// Float64 取浮点数值
+func Float64(name string, value ...float64) float64 {
+	return Default().Float64(name, value...)
+}
+
+// Float64E 与 Float64 相同，但值无法解析时返回错误
+func Float64E(name string, value ...float64) (float64, error) {
+	return Default().Float64E(name, value...)
+}
+
+// Float32 取单精度浮点数值
+func Float32(name string, value ...float32) float32 {
+	return Default().Float32(name, value...)
+}
+
+// Float64Locale 取包含千位分隔符的浮点数值
+func Float64Locale(name string, value ...float64) float64 {
+	return Default().Float64Locale(name, value...)
+}
+
+// SampleRate 取限制在 [0, 1] 之间的采样率
+func SampleRate(name string, value ...float64) float64 {
+	return Default().SampleRate(name, value...)
 }
 
 func Duration(name string, value ...time.Duration) time.Duration {
-	return env.Duration(name, value...)
+	return Default().Duration(name, value...)
+}
+
+// DurationE 与 Duration 相同，但值无法解析时返回错误
+func DurationE(name string, value ...time.Duration) (time.Duration, error) {
+	return Default().DurationE(name, value...)
+}
+
+// DurationSum 取多个时长之和
+func DurationSum(name string) time.Duration {
+	return Default().DurationSum(name)
+}
+
+// Schedule 取重试或退避的时间表
+func Schedule(name string) []time.Duration {
+	return Default().Schedule(name)
+}
+
+// Cron 取经过语法校验的 cron 表达式
+func Cron(name string) (string, error) {
+	return Default().Cron(name)
+}
+
+// Bandwidth 取换算为每秒字节数的带宽
+func Bandwidth(name string) (int64, error) {
+	return Default().Bandwidth(name)
+}
+
+// TimeOfDay 取 `HH:MM` 格式的时刻
+func TimeOfDay(name string) (hour, minute int, err error) {
+	return Default().TimeOfDay(name)
+}
+
+// Rate 取换算为每秒次数的频率
+func Rate(name string) (float64, error) {
+	return Default().Rate(name)
+}
+
+// Until 返回当前时间距指定 Unix 时间戳的时长
+func Until(name string, value ...time.Duration) time.Duration {
+	return Default().Until(name, value...)
+}
+
+// Time 取 RFC3339 格式的时间
+func Time(name string, fallback ...time.Time) time.Time {
+	return Default().Time(name, fallback...)
+}
+
+// TimeLayout 取指定格式的时间
+func TimeLayout(name, layout string, fallback ...time.Time) time.Time {
+	return Default().TimeLayout(name, layout, fallback...)
 }
 
 func Bool(name string, value ...bool) bool {
-	return env.Bool(name, value...)
+	return Default().Bool(name, value...)
+}
+
+// BoolE 与 Bool 相同，但值无法解析时返回错误
+func BoolE(name string, value ...bool) (bool, error) {
+	return Default().BoolE(name, value...)
+}
+
+// BoolOrInt 取开关或等级值
+func BoolOrInt(name string) (bool, int) {
+	return Default().BoolOrInt(name)
+}
+
+// BoolList 取布尔值列表
+func BoolList(name string, fallback ...[]bool) []bool {
+	return Default().BoolList(name, fallback...)
+}
+
+// FileMode 取八进制表示的文件权限值
+func FileMode(name string, fallback ...os.FileMode) os.FileMode {
+	return Default().FileMode(name, fallback...)
 }
 
 // List 将值按 `,` 分割并返回
 func List(name string, fallback ...[]string) []string {
-	return env.List(name, fallback...)
+	return Default().List(name, fallback...)
+}
+
+// ListSep 将值按 sep 分割并返回
+func ListSep(name, sep string, fallback ...[]string) []string {
+	return Default().ListSep(name, sep, fallback...)
+}
+
+// ListMerged 合并类目键与缺省键的字符串列表
+func ListMerged(name string) []string {
+	return Default().ListMerged(name)
+}
+
+// ListAuto 自动识别分隔符并返回字符串列表
+func ListAuto(name string) []string {
+	return Default().ListAuto(name)
+}
+
+// ListN 将值按 `,` 分割并返回，元素数量超过 max 时返回默认值
+func ListN(name string, max int, fallback ...[]string) []string {
+	return Default().ListN(name, max, fallback...)
+}
+
+// ListEscaped 将值按未转义的 `,` 分割并返回
+func ListEscaped(name string, fallback ...[]string) []string {
+	return Default().ListEscaped(name, fallback...)
+}
+
+// ListCSV 将值作为一条 CSV 记录解析并返回
+func ListCSV(name string, fallback ...[]string) []string {
+	return Default().ListCSV(name, fallback...)
+}
+
+// URLList 将值按 `,` 分割并解析为 URL 列表
+func URLList(name string, fallback ...[]*url.URL) []*url.URL {
+	return Default().URLList(name, fallback...)
+}
+
+// IPList 将值按 `,` 分割并解析为 IP 地址列表
+func IPList(name string, fallback ...[]net.IP) []net.IP {
+	return Default().IPList(name, fallback...)
+}
+
+// MAC 取硬件地址
+func MAC(name string, fallback ...net.HardwareAddr) net.HardwareAddr {
+	return Default().MAC(name, fallback...)
+}
+
+// WeightedMap 取带权重的映射
+func WeightedMap(name string, fallback ...map[string]int) map[string]int {
+	return Default().WeightedMap(name, fallback...)
+}
+
+// LookupEnumRequired 取必填的枚举值
+func LookupEnumRequired(name string, allowed []string) (string, error) {
+	return Default().LookupEnumRequired(name, allowed)
+}
+
+// Render 将值作为模板渲染
+func Render(name string, data any) (string, error) {
+	return Default().Render(name, data)
 }
 
 func Map(prefix string) map[string]string {
-	return env.Map(prefix)
+	return Default().Map(prefix)
+}
+
+// MapStrict 聚合指定前缀的值，键名冲突时返回错误
+func MapStrict(prefix string) (map[string]string, error) {
+	return Default().MapStrict(prefix)
 }
 
 func Where(filter func(name string, value string) bool) map[string]string {
-	return env.Where(filter)
+	return Default().Where(filter)
+}
+
+// Typed 返回所有值，并将值转换为推断出的类型
+func Typed() map[string]any {
+	return Default().Typed()
+}
+
+// ExportEnviron 以 `KEY=value` 的形式导出所有值
+func ExportEnviron() []string {
+	return Default().ExportEnviron()
+}
+
+// Watch 检查全局数据加载过的文件，内容发生变化时重新加载
+func Watch(ctx context.Context, onReload func(error)) error {
+	return Default().Watch(ctx, onReload)
+}
+
+// WatchChanges 检查全局数据加载过的文件，有键新增或值发生变化时将这些键值传给 onChange
+func WatchChanges(ctx context.Context, onChange func(changed map[string]string, err error)) error {
+	return Default().WatchChanges(ctx, onChange)
+}
+
+// ExportToOS 将全局数据写入进程的环境变量
+func ExportToOS(overwrite bool) error {
+	return Default().ExportToOS(overwrite)
 }
 
 // Fill 将环境变量填充到指定结构体
 func Fill(structure any) error {
-	return env.Fill(structure)
+	return Default().Fill(structure)
+}
+
+// FillAll 将环境变量填充到指定结构体，并返回所有字段的错误
+func FillAll(structure any) error {
+	return Default().FillAll(structure)
+}
+
+// MustFill 将环境变量填充到指定结构体，失败时 panic
+func MustFill(structure any) {
+	Default().MustFill(structure)
+}
+
+// JSON 将值作为 JSON 文档解码到 out 中
+func JSON(name string, out any) error {
+	return Default().JSON(name, out)
+}
+
+// Bind 将值转换并赋值给 target 所指向的变量
+func Bind(name string, target any, fallback ...any) error {
+	return Default().Bind(name, target, fallback...)
+}
+
+// MustString 取必填的字符串值，数据不存在或值为空时 panic
+func MustString(name string) string {
+	return Default().MustString(name)
+}
+
+// MustInt 取必填的整数值，数据不存在、值为空或无法解析时 panic
+func MustInt(name string) int {
+	return Default().MustInt(name)
+}
+
+// MustBool 取必填的布尔值，数据不存在、值为空或无法解析时 panic
+func MustBool(name string) bool {
+	return Default().MustBool(name)
+}
+
+// MustDuration 取必填的时长，数据不存在、值为空或无法解析时 panic
+func MustDuration(name string) time.Duration {
+	return Default().MustDuration(name)
 }
 
 // All 返回所有值
 func All() map[string]string {
-	return env.Where(func(name, value string) bool {
+	return Default().Where(func(name, value string) bool {
 		return true
 	})
 }
@@ -0,0 +1,465 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetDefault(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+
+	e := New()
+	e.LoadMap(map[string]string{"NAME": "isolated", "PORT": "8080"})
+	SetDefault(e)
+	if Default() != e {
+		t.Fatal("Default() did not return the instance passed to SetDefault")
+	}
+	if got := String("NAME"); got != "isolated" {
+		t.Errorf("String(NAME) = %q, want %q", got, "isolated")
+	}
+	if got := Int("PORT"); got != 8080 {
+		t.Errorf("Int(PORT) = %d, want 8080", got)
+	}
+	var cfg struct {
+		Name string `env:"NAME"`
+	}
+	if err := Fill(&cfg); err != nil || cfg.Name != "isolated" {
+		t.Errorf("Fill() = %v, Name = %q", err, cfg.Name)
+	}
+
+	SetDefault(prev)
+	if Default() != prev {
+		t.Fatal("SetDefault did not restore the previous instance")
+	}
+	SetDefault(nil)
+	if Default() == nil || Exists("NAME") {
+		t.Error("SetDefault(nil) should install a new empty instance")
+	}
+}
+
+// useDefault 将包级函数使用的实例替换为只包含 data 的新实例，测试结束后恢复
+func useDefault(t *testing.T, data map[string]string) Environ {
+	t.Helper()
+	prev := Default()
+	e := New()
+	e.LoadMap(data)
+	SetDefault(e)
+	t.Cleanup(func() { SetDefault(prev) })
+	return e
+}
+
+func TestSignedDefault(t *testing.T) {
+	useDefault(t, map[string]string{
+		"CACHE_DRIVER":        "redis",
+		"CACHE_DATABASE":      "1",
+		"CACHE_BOOK_DATABASE": "10",
+		"CACHE_BOOK_DEBUG":    "true",
+		"CACHE_TTL":           "30",
+		"CACHE_HOSTS":         "a,b",
+	})
+	t.Cleanup(func() { SignedDefault("", "") })
+
+	if got := ScopedString("CACHE_DRIVER"); got != "redis" {
+		t.Errorf("before SignedDefault, ScopedString(CACHE_DRIVER) = %q, want redis", got)
+	}
+
+	SignedDefault("CACHE", "BOOK")
+	if got := ScopedString("DRIVER"); got != "redis" {
+		t.Errorf("ScopedString(DRIVER) = %q, want redis", got)
+	}
+	if got := ScopedInt("DATABASE"); got != 10 {
+		t.Errorf("ScopedInt(DATABASE) = %d, want 10", got)
+	}
+	if got := ScopedBool("DEBUG"); !got {
+		t.Error("ScopedBool(DEBUG) = false, want true")
+	}
+	if got := ScopedDuration("TTL"); got != 30*time.Second {
+		t.Errorf("ScopedDuration(TTL) = %v, want 30s", got)
+	}
+	if got := ScopedList("HOSTS"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("ScopedList(HOSTS) = %q, want [a b]", got)
+	}
+}
+
+func TestDefaultConcurrentSwap(t *testing.T) {
+	useDefault(t, map[string]string{"CACHE_NAME": "x"})
+	t.Cleanup(func() { SignedDefault("", "") })
+	prev := Default()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			SignedDefault("CACHE", "")
+			SetDefault(prev)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			ScopedString("NAME")
+			String("CACHE_NAME")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestOpen(t *testing.T) {
+	global := useDefault(t, map[string]string{"NAME": "global"})
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "NAME=isolated\nAPP_ENV=dev\n")
+	writeFile(t, dir, ".env.dev", "LEVEL=debug\n")
+	extra := writeFile(t, dir, "extra.env", "EXTRA=1\n")
+
+	e, err := Open(Options{Dir: dir, Files: []string{extra}, IgnoreOS: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"NAME": "isolated", "LEVEL": "debug", "EXTRA": "1"})
+	if got := len(e.Pairs()); got != 4 {
+		t.Errorf("Open with IgnoreOS loaded %d keys, want 4", got)
+	}
+	if Default() != global || String("NAME") != "global" || Exists("LEVEL") {
+		t.Error("Open changed the global instance")
+	}
+
+	if _, err = Open(Options{Files: []string{filepath.Join(dir, "missing.env")}}); err == nil {
+		t.Error("Open should fail for a missing extra file")
+	}
+}
+
+func TestEnvFilesFor(t *testing.T) {
+	dir := filepath.Join("config", "app")
+	join := func(name string) string { return filepath.Join(dir, name) }
+	tests := []struct {
+		appEnv string
+		want   []string
+	}{
+		{"dev", []string{join(".env.defaults"), join(".env"), join(".env.local"), join(".env.dev"), join(".env.dev.local")}},
+		{"Prod", []string{join(".env.defaults"), join(".env"), join(".env.local"), join(".env.prod"), join(".env.prod.local")}},
+		{"", []string{join(".env.defaults"), join(".env"), join(".env.local")}},
+	}
+	for _, tt := range tests {
+		if got := EnvFilesFor(dir, tt.appEnv); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EnvFilesFor(%q) = %q, want %q", tt.appEnv, got, tt.want)
+		}
+	}
+}
+
+// useInit 与 useDefault 相同，并在测试结束后恢复 Init 记录的目录与状态
+func useInit(t *testing.T) Environ {
+	t.Helper()
+	prevRoot, prevEnvOnly := root, envOnly
+	t.Cleanup(func() { root, envOnly = prevRoot, prevEnvOnly })
+	return useDefault(t, nil)
+}
+
+func TestInitWithDefaultsFile(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("ENV_TEST_OS", "os")
+	dir := t.TempDir()
+	writeFile(t, dir, ".env.defaults", "ONLY_DEFAULT=d\nOVERRIDDEN=d\nLOCAL=d\nENV_TEST_OS=d\n")
+	writeFile(t, dir, ".env", "OVERRIDDEN=env\n")
+	writeFile(t, dir, ".env.local", "LOCAL=local\n")
+	e := useInit(t)
+
+	if err := InitWithDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{
+		"ONLY_DEFAULT": "d",
+		"OVERRIDDEN":   "env",
+		"LOCAL":        "local",
+		"ENV_TEST_OS":  "os",
+	})
+}
+
+func TestSet(t *testing.T) {
+	e := useDefault(t, map[string]string{"A": "1"})
+	Set("A", "2")
+	Set("B", "3")
+	assertValues(t, e, map[string]string{"A": "2", "B": "3"})
+	if got, want := e.(*environ).Pairs(), []Pair{{"A", "2"}, {"B", "3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pairs() = %v, want %v", got, want)
+	}
+}
+
+func TestUnset(t *testing.T) {
+	e := useDefault(t, nil)
+	Set("A", "1")
+	Set("B", "2")
+	Set("C", "3")
+	Unset("B")
+	Unset("MISSING")
+
+	if got, want := e.Map(""), map[string]string{"A": "1", "C": "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+	if got, want := e.(*environ).Pairs(), []Pair{{"A", "1"}, {"C", "3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pairs() = %v, want %v", got, want)
+	}
+	if e.Exists("B") {
+		t.Error("B still exists after Unset")
+	}
+	Set("B", "4")
+	if got, want := e.(*environ).Pairs(), []Pair{{"A", "1"}, {"C", "3"}, {"B", "4"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pairs() = %v after setting B again, want %v", got, want)
+	}
+}
+
+func TestReloadAll(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	dir := t.TempDir()
+	filename := writeFile(t, dir, ".env", "A=1\nB=2\nC=3\n")
+	e := useInit(t)
+	if _, err := ReloadAll(); err != ErrNotInitialized {
+		t.Fatalf("ReloadAll() before Init = %v, want ErrNotInitialized", err)
+	}
+	if err := InitWithDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, "", filename, "A=1\nB=20\nD=4\n")
+	changed, err := ReloadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"B": "20", "C": "", "D": "4"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("ReloadAll() = %v, want %v", changed, want)
+	}
+	assertValues(t, e, map[string]string{"A": "1", "B": "20", "C": "", "D": "4"})
+
+	// 加载失败时保留原有数据
+	writeFile(t, "", filename, "A=\"unterminated\n")
+	if _, err := ReloadAll(); err == nil {
+		t.Error("ReloadAll() with a broken file should fail")
+	}
+	assertValues(t, e, map[string]string{"A": "1", "B": "20"})
+}
+
+func TestReloadLastInit(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	dir := t.TempDir()
+	filename := writeFile(t, dir, ".env", "A=1\n")
+	e := useInit(t)
+	if err := Reload(); err != ErrNotInitialized {
+		t.Fatalf("Reload() before Init = %v, want ErrNotInitialized", err)
+	}
+	if err := InitWithDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, "", filename, "A=2\nB=3\n")
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"A": "2", "B": "3"})
+	if got, want := Path("config"), filepath.Join(dir, "config"); got != want {
+		t.Errorf("Path(config) = %q after Reload, want %q", got, want)
+	}
+
+	// 只支持通过 New 创建的全局实例
+	SetDefault(struct{ Environ }{e})
+	if err := Reload(); err == nil {
+		t.Error("Reload() with a custom Environ should fail")
+	}
+	if _, err := ReloadAll(); err == nil {
+		t.Error("ReloadAll() with a custom Environ should fail")
+	}
+}
+
+func TestReloadFile(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "app.env", "A=1\n")
+	e := useDefault(t, nil)
+	if err := Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, "", filename, "A=1\nB=2\n")
+	changed, err := ReloadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"B": "2"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("ReloadFile() = %v, want %v", changed, want)
+	}
+	assertValues(t, e, map[string]string{"A": "1", "B": "2"})
+}
+
+func TestReloadAllConcurrentReads(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	dir := t.TempDir()
+	filename := writeFile(t, dir, ".env", "A=0\nB=0\n")
+	e := useInit(t)
+	if err := InitWithDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// 读取方只会看到 A 与 B 同时更新后的状态
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				pairs := e.(*environ).Pairs()
+				values := map[string]string{}
+				for _, pair := range pairs {
+					values[pair.Key] = pair.Value
+				}
+				if values["A"] != values["B"] {
+					t.Errorf("partial state: A=%q B=%q", values["A"], values["B"])
+					return
+				}
+			}
+		}()
+	}
+	for n := 1; n <= 20; n++ {
+		writeFile(t, "", filename, fmt.Sprintf("A=%d\nB=%d\n", n, n))
+		if _, err := ReloadAll(); err != nil {
+			t.Error(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+	assertValues(t, e, map[string]string{"A": "20", "B": "20"})
+}
+
+func TestInitConcurrent(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	e := useInit(t)
+	dirs := make([]string, 8)
+	for i := range dirs {
+		dirs[i] = t.TempDir()
+		writeFile(t, dirs[i], ".env", fmt.Sprintf("DIR=%s\nINDEX=%d\n", dirs[i], i))
+	}
+
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			if err := InitWithDir(dir); err != nil {
+				t.Error(err)
+			}
+		}(dir)
+	}
+	wg.Wait()
+
+	// 最后完成的 Init 决定全部状态：数据与 Path 来自同一个目录，且只包含该目录的文件
+	dir := e.String("DIR")
+	if dir == "" || Path() != dir {
+		t.Fatalf("DIR = %q, Path() = %q, want the same directory", dir, Path())
+	}
+	index := e.Int("INDEX", -1)
+	if index < 0 || dirs[index] != dir {
+		t.Errorf("INDEX = %d does not match DIR = %q", index, dir)
+	}
+	if files := e.(*environ).files; len(files) != 1 || files[0].name != filepath.Join(dir, ".env") {
+		t.Errorf("loaded files = %v, want only %s", files, filepath.Join(dir, ".env"))
+	}
+}
+
+func TestLoadMap(t *testing.T) {
+	e := useDefault(t, map[string]string{"A": "1", "B": "2"})
+	LoadMap(map[string]string{"B": "20", "C": "3"})
+	assertValues(t, e, map[string]string{"A": "1", "B": "20", "C": "3"})
+}
+
+// chdir 切换工作目录并在测试结束后恢复
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(prev) })
+}
+
+func TestInitEnvOnly(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_VALUE", "os")
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "STRAY=1\nENV_TEST_OS_VALUE=file\n")
+	chdir(t, dir)
+	e := useInit(t)
+
+	if err := InitEnvOnly(); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"STRAY": "", "ENV_TEST_OS_VALUE": "os"})
+	if wd, _ := os.Getwd(); Path() != wd {
+		t.Errorf("Path() = %q, want the working directory %q", Path(), wd)
+	}
+
+	// 重新加载同样不会读取文件
+	if _, err := ReloadAll(); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"STRAY": "", "ENV_TEST_OS_VALUE": "os"})
+}
+
+// usePostLoadHook 注册加载后处理函数，并在测试结束后恢复原有的处理函数
+func usePostLoadHook(t *testing.T, fn func(e Environ)) {
+	t.Helper()
+	hooksMu.Lock()
+	prev := make([]func(e Environ), len(postLoadHooks))
+	copy(prev, postLoadHooks)
+	hooksMu.Unlock()
+	t.Cleanup(func() {
+		hooksMu.Lock()
+		postLoadHooks = prev
+		hooksMu.Unlock()
+	})
+	AddPostLoadHook(fn)
+}
+
+func TestAddPostLoadHook(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "MYAPP_HOST=example.com\nMYAPP_PORT=8080\n")
+	e := useInit(t)
+	var calls []string
+	usePostLoadHook(t, func(e Environ) {
+		calls = append(calls, "derive")
+		e.Set("ADDR", e.String("MYAPP_HOST")+":"+e.String("MYAPP_PORT"))
+	})
+	usePostLoadHook(t, func(e Environ) {
+		calls = append(calls, "upper")
+		e.Set("ADDR", strings.ToUpper(e.String("ADDR")))
+	})
+
+	if err := InitWithDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.String("ADDR"); got != "EXAMPLE.COM:8080" {
+		t.Errorf("ADDR = %q, want EXAMPLE.COM:8080", got)
+	}
+	if want := []string{"derive", "upper"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("hooks ran as %v, want %v", calls, want)
+	}
+
+	// ReloadAll 对新数据调用处理函数
+	writeFile(t, "", filepath.Join(dir, ".env"), "MYAPP_HOST=other.com\nMYAPP_PORT=9090\n")
+	if _, err := ReloadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.String("ADDR"); got != "OTHER.COM:9090" {
+		t.Errorf("ADDR = %q after ReloadAll, want OTHER.COM:9090", got)
+	}
+}
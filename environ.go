@@ -1,19 +1,42 @@
 package env
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 var _ Signer = &environ{}
 
 type environ struct {
 	inner
-	keys   []string
-	values []string
-	mu     sync.RWMutex
+	keys      []string          // 按写入顺序排列的键名
+	values    map[string]string // 键值，与 keys 保持一致
+	auditor   func(key string, found bool)
+	expand    bool
+	normalize bool
+	lazy      []string
+	blocked   map[string]bool
+	missing   map[string][]string // 加载文件时值中无法解析的引用
+	osEnv     map[string]string
+	schemes   map[string]func(ref string) (string, error)
+	resolved  sync.Map
+	observers map[string][]observer
+	files     []loadedFile
+	mu        sync.RWMutex
 }
 
 func New() Environ {
@@ -21,81 +44,1112 @@ func New() Environ {
 	e.inner.lookup = e.lookup
 	e.inner.exists = e.exists
 	e.inner.iter = e.iter
+	e.inner.qualify = func(key string) string { return key }
+	e.inner.root = e.lookup
+	e.inner.save = e.Save
+	e.inner.candidates = e.candidates
+	e.inner.withEncoding = e.withEncoding
 	return e
 }
 
-// Load 加载环境变量文件
+// Load 加载环境变量文件，未指定文件时加载当前目录下的 .env 文件，
+// 文件中的值会覆盖已存在的键，多个文件中的相同键以后面的文件为准
 func (e *environ) Load(filenames ...string) error {
-	data, err := godotenv.Read(filenames...)
-	if err == nil {
+	return e.load(true, filenames...)
+}
+
+// LoadNoOverride 加载环境变量文件，但只补充尚不存在的键，已存在的键（如系统环境变量）保持不变，
+// 多个文件中的相同键以前面的文件为准，与 godotenv 的 Load 行为一致
+func (e *environ) LoadNoOverride(filenames ...string) error {
+	return e.load(false, filenames...)
+}
+
+func (e *environ) load(override bool, filenames ...string) error {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+	pairs := e.Pairs()
+	existing := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		existing[pair.Key] = true
+	}
+	data := make(map[string]string)
+	missing := make(map[string][]string)
+	vars := e.interpolation(pairs)
+	for _, filename := range filenames {
+		values, unresolved, err := readFile(filename, vars)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			if _, ok := data[key]; !override && (ok || existing[key]) {
+				continue
+			}
+			data[key] = value
+			vars.values[key] = value
+			missing[key] = unresolved[key]
+		}
+	}
+	if override {
 		e.Save(data)
+	} else {
+		e.fill(data)
+	}
+	e.remember(data, missing)
+	e.track(override, filenames...)
+	return nil
+}
+
+// fill 只保存尚不存在的键
+func (e *environ) fill(data map[string]string) {
+	e.mu.Lock()
+	keys := make([]string, 0, len(data))
+	for key, value := range data {
+		if _, ok := e.values[key]; !ok {
+			e.set(key, value)
+			keys = append(keys, key)
+		}
+	}
+	e.mu.Unlock()
+	e.notify(keys...)
+}
+
+// Reload 重新加载单个文件，返回因此新增或值发生变化的键值（不包含被禁止读取的键）
+func (e *environ) Reload(filename string) (changed map[string]string, err error) {
+	data, missing, err := readFile(filename, e.interpolation(e.Pairs()))
+	if err != nil {
+		return nil, err
+	}
+	changed = make(map[string]string)
+	e.mu.Lock()
+	for key, value := range data {
+		if old, ok := e.values[key]; !ok || old != value {
+			e.set(key, value)
+			if !e.blocked[key] {
+				changed[key] = value
+			}
+		}
+	}
+	e.mu.Unlock()
+	e.remember(data, missing)
+	e.track(true, filename)
+	keys := make([]string, 0, len(changed))
+	for key := range changed {
+		keys = append(keys, key)
+	}
+	e.notify(keys...)
+	return changed, nil
+}
+
+// swap 使用 src 的数据（包括系统环境变量快照与已加载的文件列表）一次性替换当前数据，
+// 返回新增、值发生变化以及被移除（值为空字符串）的键值，被禁止读取的键不会出现在结果中
+func (e *environ) swap(src *environ) (changed map[string]string) {
+	src.mu.RLock()
+	keys := append([]string(nil), src.keys...)
+	values := make(map[string]string, len(src.values))
+	for key, value := range src.values {
+		values[key] = value
+	}
+	osEnv := src.osEnv
+	files := append([]loadedFile(nil), src.files...)
+	missing := make(map[string][]string, len(src.missing))
+	for key, names := range src.missing {
+		missing[key] = names
+	}
+	src.mu.RUnlock()
+
+	changed = make(map[string]string)
+	e.mu.Lock()
+	for key, value := range values {
+		if old, ok := e.values[key]; (!ok || old != value) && !e.blocked[key] {
+			changed[key] = value
+		}
+	}
+	for key := range e.values {
+		if _, ok := values[key]; !ok && !e.blocked[key] {
+			changed[key] = ""
+		}
+	}
+	e.keys, e.values, e.osEnv, e.files, e.missing = keys, values, osEnv, files, missing
+	e.mu.Unlock()
+
+	names := make([]string, 0, len(changed))
+	for key := range changed {
+		names = append(names, key)
+	}
+	e.notify(names...)
+	return changed
+}
+
+// LoadMap 加载已有的键值，与 Load 相同，已存在的键会被覆盖，适合在测试中准备数据
+func (e *environ) LoadMap(data map[string]string) {
+	e.Save(data)
+}
+
+// LoadReader 从 r 中读取并加载环境变量，格式与 .env 文件相同，
+// 适合加载嵌入程序、从网络获取或在内存中生成的配置，值会覆盖已存在的键
+func (e *environ) LoadReader(r io.Reader) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data, missing, err := parse(src, e.interpolation(e.Pairs()))
+	if err != nil {
+		return err
+	}
+	e.Save(data)
+	e.remember(data, missing)
+	return nil
+}
+
+// remember 记录加载时值中无法解析的引用，供 CheckReferences 使用，
+// 只记录值仍为 data 中对应值的键（LoadNoOverride 等不会覆盖已存在的键）
+func (e *environ) remember(data map[string]string, missing map[string][]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, names := range missing {
+		if value, ok := e.values[key]; len(names) > 0 && ok && value == data[key] {
+			if e.missing == nil {
+				e.missing = make(map[string][]string)
+			}
+			e.missing[key] = names
+		}
+	}
+}
+
+// readFile 读取并解析环境变量文件
+func readFile(filename string, vars expandVars) (map[string]string, map[string][]string, error) {
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		return nil, nil, fmt.Errorf("env: %q is a directory", filename)
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parse(src, vars)
+}
+
+// parse 解析环境变量文件的内容，会去除开头的 UTF-8 BOM，
+// 并将 Windows 风格的换行符（CRLF）统一为 LF。
+//
+// 值中的 `${KEY}` 与 `$KEY` 引用会按照 os.Expand 的规则在加载时展开，依次查找文件中之前定义的键
+// 与 vars，都找不到时替换为空字符串；展开只进行一次，因此循环引用不会导致死循环。
+// `$$` 与 `\$` 表示字面量 `$`，单引号包裹的值不会展开。
+//
+// 第二个返回值记录每个键的值中无法解析（已被替换为空字符串）的引用。
+func parse(src []byte, vars expandVars) (map[string]string, map[string][]string, error) {
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	src, keys := markDollars(src)
+	data, err := godotenv.UnmarshalBytes(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	defined := make(map[string]string, len(data))
+	lookup := func(name string) (string, bool) {
+		if value, ok := defined[name]; ok && !vars.blocked[name] {
+			return value, true
+		}
+		return vars.lookup(name)
+	}
+	missing := make(map[string][]string)
+	expand := func(key string) {
+		if _, ok := defined[key]; ok {
+			return
+		}
+		value, unresolved := expandValue(data[key], lookup)
+		if len(unresolved) > 0 {
+			missing[key] = unresolved
+		}
+		data[key] = value
+		defined[key] = value
+	}
+	// 按照键在文件中出现的顺序展开，之后的键才能引用之前的键
+	for _, key := range keys {
+		if _, ok := data[key]; ok {
+			expand(key)
+		}
+	}
+	for key := range data {
+		expand(key)
+	}
+	return data, missing, nil
+}
+
+// 加载时替换 `$` 使用的占位符，godotenv 不会展开它们：
+// dollarRef 表示需要展开的引用，dollarLiteral 表示字面量 `$`
+const (
+	dollarRef     = "\uE000"
+	dollarLiteral = "\uE001"
+)
+
+// markDollars 将单引号包裹的值以外的 `$$` 与 `\$` 替换为 dollarLiteral，其余的 `$` 替换为 dollarRef，
+// 并按出现的顺序返回文件中定义的键名
+func markDollars(src []byte) ([]byte, []string) {
+	var keys []string
+	var quote byte // 跨越多行的值使用的引号
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		single := quote == '\''
+		if quote == 0 {
+			key, value, ok := bytes.Cut(line, []byte("="))
+			key = bytes.TrimSpace(key)
+			if !ok || len(key) == 0 || key[0] == '#' {
+				continue
+			}
+			keys = append(keys, string(bytes.TrimSpace(bytes.TrimPrefix(key, []byte("export ")))))
+			if value = bytes.TrimSpace(value); len(value) > 0 && (value[0] == '\'' || value[0] == '"') {
+				single = value[0] == '\''
+				if !closesQuote(value[1:], value[0]) {
+					quote = value[0]
+				}
+			}
+		} else if closesQuote(line, quote) {
+			quote = 0
+		}
+		if single || !bytes.Contains(line, []byte("$")) {
+			continue
+		}
+		line = bytes.ReplaceAll(line, []byte("$$"), []byte(dollarLiteral))
+		line = bytes.ReplaceAll(line, []byte(`\$`), []byte(dollarLiteral))
+		lines[i] = bytes.ReplaceAll(line, []byte("$"), []byte(dollarRef))
+	}
+	return bytes.Join(lines, []byte("\n")), keys
+}
+
+// closesQuote 判断 line 中是否有未被反斜杠转义的引号 quote，规则与 godotenv 相同
+func closesQuote(line []byte, quote byte) bool {
+	for i, c := range line {
+		if c == quote && (i == 0 || line[i-1] != '\\') {
+			return true
+		}
 	}
-	return err
+	return false
+}
+
+// expandValue 使用 os.Expand 展开 markDollars 标记的引用，返回展开后的值以及无法解析的引用
+func expandValue(value string, lookup func(string) (string, bool)) (string, []string) {
+	if !strings.Contains(value, dollarRef) && !strings.Contains(value, dollarLiteral) {
+		return value, nil
+	}
+	var unresolved []string
+	value = os.Expand(strings.ReplaceAll(value, dollarRef, "$"), func(name string) string {
+		v, ok := lookup(name)
+		if !ok {
+			unresolved = append(unresolved, name)
+		}
+		return v
+	})
+	return strings.ReplaceAll(value, dollarLiteral, "$"), unresolved
+}
+
+// expandVars 加载文件时用于展开引用的数据，已加载的数据优先于系统环境变量，
+// 被禁止读取的键不会参与展开
+type expandVars struct {
+	values  map[string]string
+	blocked map[string]bool
+}
+
+func (v expandVars) lookup(name string) (string, bool) {
+	if v.blocked[name] {
+		return "", false
+	}
+	if value, ok := v.values[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// interpolation 返回加载文件时用于展开引用的数据，pairs 为当前未被禁止读取的数据
+func (e *environ) interpolation(pairs []Pair) expandVars {
+	vars := expandVars{values: make(map[string]string, len(pairs)), blocked: make(map[string]bool)}
+	for _, pair := range pairs {
+		vars.values[pair.Key] = pair.Value
+	}
+	e.mu.RLock()
+	for key := range e.blocked {
+		vars.blocked[key] = true
+	}
+	e.mu.RUnlock()
+	return vars
+}
+
+// quote 将值转义并使用双引号包裹，转义规则与 godotenv 相同
+func quote(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range value {
+		switch c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\', '"', '!', '$', '`':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+var (
+	// 可以写入 .env 文件的键名
+	dumpKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	// 无需使用引号包裹的值
+	plainValue = regexp.MustCompile(`^[A-Za-z0-9_./:,@+=%-]*$`)
+)
+
+// Dump 按写入顺序将所有数据（不包含被禁止读取的键）序列化为 .env 格式，
+// 包含空白或特殊字符的值会使用双引号包裹并转义，以 `"` 结尾的值使用单引号包裹，
+// 以反斜杠结尾的值不使用引号，无法表示的键或值会返回错误
+func (e *environ) Dump() (string, error) {
+	var b strings.Builder
+	for _, pair := range e.Pairs() {
+		if !dumpKey.MatchString(pair.Key) {
+			return "", fmt.Errorf("env: key %q cannot be written to a .env file", pair.Key)
+		}
+		b.WriteString(pair.Key)
+		b.WriteByte('=')
+		switch {
+		case plainValue.MatchString(pair.Value):
+			b.WriteString(pair.Value)
+		case strings.HasSuffix(pair.Value, "\\"):
+			// godotenv 会将结尾的 `\"` 与 `\'` 视为转义的引号，只能不加引号写入
+			if !bareValue(pair.Value) {
+				return "", fmt.Errorf("env: value of %q cannot be written to a .env file", pair.Key)
+			}
+			b.WriteString(pair.Value)
+		case strings.HasSuffix(pair.Value, `"`):
+			// godotenv 无法解析紧挨着结尾引号的转义引号（`"a\""`），改用不做任何转义的单引号
+			if strings.ContainsAny(pair.Value, "'\r\n") {
+				return "", fmt.Errorf("env: value of %q cannot be written to a .env file", pair.Key)
+			}
+			b.WriteString("'" + pair.Value + "'")
+		default:
+			b.WriteString(quote(pair.Value))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// bareValue 判断值能否不加引号写入：不包含换行、`#` 与 `$`，
+// 首尾没有空白，也不以引号开头
+func bareValue(value string) bool {
+	return value == strings.TrimSpace(value) &&
+		!strings.ContainsAny(value, "\r\n#$") &&
+		!strings.HasPrefix(value, "'") && !strings.HasPrefix(value, `"`)
+}
+
+// WriteFile 将 Dump 的结果写入文件，先写入同目录下的临时文件再重命名，
+// 因此不会留下写了一半的文件，新文件的权限为 0600
+func (e *environ) WriteFile(path string) error {
+	data, err := e.Dump()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadJSON 加载 JSON 格式的配置文件，嵌套的对象会被展开为使用下划线连接的大写键名，
+// 比如 `{"cache":{"book":{"database":10}}}` 会被保存为 `CACHE_BOOK_DATABASE=10`。
+// 元素均为标量的数组会被合并为使用英文逗号分割的值，其它数组则使用下标作为键名的一部分。
+func (e *environ) LoadJSON(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var doc map[string]any
+	decoder := json.NewDecoder(f)
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("env: cannot decode %q: %w", filename, err)
+	}
+
+	data := make(map[string]string)
+	flatten(data, "", doc)
+	e.Save(data)
+	return nil
+}
+
+// LoadYAML 加载 YAML 格式的配置文件，键名的展开规则与 LoadJSON 相同，
+// 标量保持文件中的原始写法（如 `1.10` 不会变为 `1.1`），`null` 与 `~` 保存为空字符串
+func (e *environ) LoadYAML(filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(src, &doc); err != nil {
+		return fmt.Errorf("env: cannot decode %q: %w", filename, err)
+	}
+
+	data := make(map[string]string)
+	if value := yamlValue(&doc); value != nil {
+		root, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("env: cannot decode %q: top-level value must be a mapping", filename)
+		}
+		flatten(data, "", root)
+	}
+	e.Save(data)
+	return nil
+}
+
+// LoadOS 加载系统的环境变量，同时保留一份快照供 ResetKeyToOS 使用
+func (e *environ) LoadOS() {
+	result := make(map[string]string)
+	for _, value := range os.Environ() {
+		parts := strings.SplitN(value, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		val := strings.TrimSpace(parts[1])
+		result[key] = val
+	}
+	e.mu.Lock()
+	e.osEnv = make(map[string]string, len(result))
+	for key, value := range result {
+		e.osEnv[key] = value
+	}
+	e.mu.Unlock()
+	e.Save(result)
+}
+
+// ResetKeyToOS 将指定键恢复为 LoadOS 时系统环境变量中的值，
+// 当时系统中不存在该键时将其移除，未调用过 LoadOS 时使用当前的系统环境变量
+func (e *environ) ResetKeyToOS(key string) {
+	e.mu.Lock()
+	var value string
+	var ok bool
+	if e.osEnv != nil {
+		value, ok = e.osEnv[key]
+	} else {
+		value, ok = os.LookupEnv(key)
+		value = strings.TrimSpace(value)
+	}
+	if ok {
+		e.set(key, value)
+	} else {
+		e.remove(key)
+	}
+	e.mu.Unlock()
+	e.notify(key)
 }
 
 // Save 保存数据到缓存的环境变量里面
 func (e *environ) Save(data map[string]string) {
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	keys := make([]string, 0, len(data))
 	for key, value := range data {
-		if i := e.index(key); i > -1 {
-			e.values[i] = value
-		} else {
-			e.keys = append(e.keys, key)
-			e.values = append(e.values, value)
+		e.set(key, value)
+		keys = append(keys, key)
+	}
+	e.mu.Unlock()
+	e.notify(keys...)
+}
+
+// Set 设置单个键的值，已存在的键会被覆盖
+func (e *environ) Set(key, value string) {
+	e.Save(map[string]string{key: value})
+}
+
+// Unset 移除单个键，键不存在时不做任何处理
+func (e *environ) Unset(key string) {
+	e.mu.Lock()
+	e.remove(key)
+	e.mu.Unlock()
+	e.notify(key)
+}
+
+// With 使用 overrides 临时覆盖数据并执行 fn，fn 返回（包括发生 panic）后恢复原有数据，
+// 覆盖前不存在的键会被移除。
+func (e *environ) With(overrides map[string]string, fn func()) {
+	e.mu.Lock()
+	prev := make(map[string]string)
+	for key, value := range overrides {
+		if value, ok := e.values[key]; ok {
+			prev[key] = value
 		}
+		e.set(key, value)
 	}
+	e.mu.Unlock()
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	e.notify(keys...)
+
+	defer func() {
+		e.mu.Lock()
+		for _, key := range keys {
+			if value, ok := prev[key]; ok {
+				e.set(key, value)
+			} else {
+				e.remove(key)
+			}
+		}
+		e.mu.Unlock()
+		e.notify(keys...)
+	}()
+
+	fn()
 }
 
 func (e *environ) Signed(prefix, category string) Signer {
 	return newSigner(prefix, category, e)
 }
 
-func (e *environ) index(key string) int {
-	if e.keys != nil {
-		for i, s := range e.keys {
-			if s == key {
-				return i
+// 返回一个根作用域的查询器，其 Bytes 方法会按照 enc 解码数据
+func (e *environ) withEncoding(enc Encoding) Signer {
+	s := newSigner("", "", e).(*signer)
+	s.encoding = enc
+	return s
+}
+
+// Pair 一组键值
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Pairs 按写入顺序返回所有键值的副本（不包含被禁止读取的键）
+func (e *environ) Pairs() []Pair {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	pairs := make([]Pair, 0, len(e.keys))
+	for _, key := range e.keys {
+		if !e.blocked[key] {
+			pairs = append(pairs, Pair{Key: key, Value: e.values[key]})
+		}
+	}
+	return pairs
+}
+
+// Format 实现 fmt.Formatter，按写入顺序每行输出一组 `KEY=value`，
+// 敏感键（见 IsSensitiveKey）的值会被替换为 `******`。
+//
+// 由于 String(key, ...) 已用于读取数据，这里无法实现 fmt.Stringer，
+// 但 fmt.Sprintf("%v", e) 等调用会得到相同的效果。
+func (e *environ) Format(f fmt.State, verb rune) {
+	for i, pair := range e.Pairs() {
+		if i > 0 {
+			fmt.Fprint(f, "\n")
+		}
+		value := pair.Value
+		if IsSensitiveKey(pair.Key) {
+			value = "******"
+		}
+		fmt.Fprintf(f, "%s=%s", pair.Key, value)
+	}
+}
+
+// ChangesSince 与之前保存的快照对比，返回新增、移除和值发生变化的键，
+// 新增与变化的键按写入顺序排列，移除的键按字母顺序排列。
+// 被禁止读取的键视为不存在，快照中的这类键会作为移除的键返回。
+func (e *environ) ChangesSince(snapshot map[string]string) (added, removed, changed []string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, key := range e.keys {
+		if e.blocked[key] {
+			continue
+		}
+		if value, ok := snapshot[key]; !ok {
+			added = append(added, key)
+		} else if value != e.values[key] {
+			changed = append(changed, key)
+		}
+	}
+	for key := range snapshot {
+		if _, ok := e.values[key]; e.blocked[key] || !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return
+}
+
+// 匹配值中的 `${KEY}` 引用
+var referencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// CheckReferences 检查所有值中的 `${KEY}` 引用，按出现顺序返回无法解析（不存在）的键名，
+// 被禁止读取的键既不会被检查，也视为无法解析。
+//
+// 加载文件时引用会被展开，无法解析的引用已被替换为空字符串，这些引用在加载时被记录下来，
+// 即使之后定义了对应的键也会被返回，直到引用它们的键被重新写入。
+func (e *environ) CheckReferences() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var missing []string
+	seen := make(map[string]bool)
+	for _, key := range e.keys {
+		if e.blocked[key] {
+			continue
+		}
+		for _, name := range e.missing[key] {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
 			}
 		}
+		for _, match := range referencePattern.FindAllStringSubmatch(e.values[key], -1) {
+			name := match[1]
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if _, ok := e.values[name]; e.blocked[name] || !ok {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing
+}
+
+// 设置键值，新增的键追加到写入顺序的末尾，调用方需持有写锁
+func (e *environ) set(key, value string) {
+	if e.values == nil {
+		e.values = make(map[string]string)
+	}
+	if _, ok := e.values[key]; !ok {
+		e.keys = append(e.keys, key)
+	}
+	e.values[key] = value
+	delete(e.missing, key)
+}
+
+// 移除键值，调用方需持有写锁
+func (e *environ) remove(key string) {
+	if _, ok := e.values[key]; !ok {
+		return
+	}
+	delete(e.values, key)
+	delete(e.missing, key)
+	for i, k := range e.keys {
+		if k == key {
+			e.keys = append(e.keys[:i:i], e.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// candidates 根作用域没有类目，仅返回键本身的值
+func (e *environ) candidates(key string) []string {
+	if value, ok := e.lookup(key); ok {
+		return []string{value}
 	}
-	return -1
+	return nil
 }
 
 // 查看环境变量值，如果不存在或值为空，返回的第二个参数的值则为false。
+// 开启引用展开后，值中的 `${KEY}` 会使用对应键的值替换。
 func (e *environ) lookup(key string) (string, bool) {
+	value, found := e.lookupRaw(key)
+	if found && e.expanding() {
+		value = os.Expand(value, func(name string) string {
+			v, _ := e.lookupRaw(name)
+			return v
+		})
+		found = len(value) > 0
+	}
+	if found {
+		value, found = e.resolve(value)
+	}
+	return value, found
+}
+
+// RegisterScheme 注册外部数据解析器，值为 `{scheme}://{ref}` 形式时，读取时会使用
+// resolver 解析 ref 得到实际的值（结果会被缓存），解析失败时视为数据不存在
+func (e *environ) RegisterScheme(scheme string, resolver func(ref string) (string, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.schemes == nil {
+		e.schemes = make(map[string]func(ref string) (string, error))
+	}
+	e.schemes[scheme] = resolver
+}
+
+// resolve 使用注册的解析器解析值，未匹配任何解析器时原样返回
+func (e *environ) resolve(value string) (string, bool) {
+	scheme, ref, found := strings.Cut(value, "://")
+	if !found {
+		return value, true
+	}
+	e.mu.RLock()
+	resolver := e.schemes[scheme]
+	e.mu.RUnlock()
+	if resolver == nil {
+		return value, true
+	}
+	if v, ok := e.resolved.Load(value); ok {
+		return v.(string), true
+	}
+	v, err := resolver(ref)
+	if err != nil {
+		return "", false
+	}
+	e.resolved.Store(value, v)
+	return v, len(v) > 0
+}
+
+// 查看未展开引用的原始值
+func (e *environ) lookupRaw(key string) (string, bool) {
+	value, exists := e.get(key)
+	if !exists && e.loadLazy() {
+		value, exists = e.get(key)
+	}
+	found := exists && len(value) > 0
+	e.mu.RLock()
+	auditor := e.auditor
+	e.mu.RUnlock()
+	if auditor != nil {
+		auditor(key, found)
+	}
+	return value, found
+}
+
+func (e *environ) get(key string) (string, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	if i := e.index(key); i > -1 {
-		v := e.values[i]
-		return v, len(v) > 0
+	value, ok := e.getLocked(key)
+	if !ok && e.normalize {
+		if normalized := normalizeKey(key); normalized != key {
+			value, ok = e.getLocked(normalized)
+		}
 	}
-	return "", false
+	return value, ok
 }
 
-// 判断环境变量是否存在
-func (e *environ) exists(key string) bool {
+// getLocked 读取键值，调用方需持有读锁
+func (e *environ) getLocked(key string) (string, bool) {
+	if e.blocked[key] {
+		return "", false
+	}
+	value, ok := e.values[key]
+	return value, ok
+}
+
+// normalizeKey 将键名中的 `.` 替换为 `_` 并转换为大写，如 `cache.book.database` 转换为 `CACHE_BOOK_DATABASE`
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// RegisterLazyFile 注册延迟加载的环境变量文件，文件不会立即读取，
+// 而是在第一次查询不到数据时才加载，并且只会补充尚不存在的键。
+// 延迟加载时发生的错误（包括文件不存在）会被忽略。
+func (e *environ) RegisterLazyFile(filename string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lazy = append(e.lazy, filename)
+}
+
+// 加载所有尚未加载的延迟文件，没有待加载的文件时返回 false
+func (e *environ) loadLazy() bool {
+	e.mu.Lock()
+	filenames := e.lazy
+	e.lazy = nil
+	e.mu.Unlock()
+	if len(filenames) == 0 {
+		return false
+	}
+	for _, filename := range filenames {
+		data, missing, err := readFile(filename, e.interpolation(e.Pairs()))
+		if err != nil {
+			continue
+		}
+		e.fill(data)
+		e.remember(data, missing)
+	}
+	return true
+}
+
+// SetBlocklist 设置禁止读取的键，这些键即使存在也不会被查询或遍历到，
+// 每次调用都会替换之前的设置，不传参数时清除禁止列表
+func (e *environ) SetBlocklist(keys ...string) {
+	blocked := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		blocked[key] = true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.blocked = blocked
+}
+
+// SetExpand 设置是否在读取时展开值中的 `${KEY}` 引用，默认不展开
+func (e *environ) SetExpand(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expand = enabled
+}
+
+// SetNormalizeKeys 设置是否在读取时规范化键名，开启后按原键名查找不到数据时，
+// 会将键名中的 `.` 替换为 `_` 并转换为大写后再次查找，默认不开启
+func (e *environ) SetNormalizeKeys(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.normalize = enabled
+}
+
+func (e *environ) expanding() bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return e.index(key) > -1
+	return e.expand
+}
+
+// SetReadAuditor 设置读取审计函数，每次查询数据（无论是否命中）时都会被调用，
+// 可用于记录访问过哪些配置，传入 nil 时取消审计。
+func (e *environ) SetReadAuditor(auditor func(key string, found bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.auditor = auditor
+}
+
+// 判断环境变量是否存在
+func (e *environ) exists(key string) bool {
+	_, exists := e.get(key)
+	if !exists && e.loadLazy() {
+		_, exists = e.get(key)
+	}
+	return exists
 }
 
+// iter 在创建时获取数据的快照，遍历过程中的写入不会影响本次遍历
 func (e *environ) iter() func() (key string, value string, ok bool) {
-	var pos int32 = -1
+	pairs := e.Pairs()
+	var index int
 	return func() (key string, value string, ok bool) {
-		index := int(atomic.AddInt32(&pos, 1))
-		if index >= len(e.keys) {
+		if index >= len(pairs) {
 			return "", "", false
 		}
-		e.mu.RLock()
-		defer e.mu.RUnlock()
-		return e.keys[index], e.values[index], true
+		pair := pairs[index]
+		index++
+		return pair.Key, pair.Value, true
+	}
+}
+
+// ExportToOS 将所有数据（不包含被禁止读取的键）写入进程的环境变量，
+// 便于只通过 os.Getenv 读取配置的第三方库使用；overwrite 为 false 时
+// 跳过系统中已存在的环境变量
+func (e *environ) ExportToOS(overwrite bool) error {
+	var errs []error
+	for _, pair := range e.Pairs() {
+		if !overwrite {
+			if _, ok := os.LookupEnv(pair.Key); ok {
+				continue
+			}
+		}
+		if err := os.Setenv(pair.Key, pair.Value); err != nil {
+			errs = append(errs, fmt.Errorf("env: cannot export %q: %w", pair.Key, err))
+		}
 	}
+	return errors.Join(errs...)
 }
 
 func (e *environ) Clean() {
 	e.mu.Lock()
-	defer e.mu.Unlock()
 	e.keys = nil
 	e.values = nil
+	e.missing = nil
+	e.files = nil
+	keys := make([]string, 0, len(e.observers))
+	for key := range e.observers {
+		keys = append(keys, key)
+	}
+	e.mu.Unlock()
+	e.notify(keys...)
+}
+
+// BindAtomicString 返回一个与 key 绑定的原子值，key 的数据发生变化时会自动更新，
+// 读取时无需加锁，适合需要热更新的配置。
+//
+// 绑定会一直保留（原子值也不会被回收），直到使用该原子值调用 Unbind，
+// 因此只应绑定长期使用的配置，临时绑定的值用完后需要调用 Unbind。
+func (e *environ) BindAtomicString(key string) *atomic.Pointer[string] {
+	p := new(atomic.Pointer[string])
+	e.observe(key, p, func() {
+		value := e.String(key)
+		p.Store(&value)
+	})
+	return p
+}
+
+// BindAtomicInt 返回一个与 key 绑定的整型原子值，规则同 BindAtomicString
+func (e *environ) BindAtomicInt(key string) *atomic.Int64 {
+	p := new(atomic.Int64)
+	e.observe(key, p, func() {
+		p.Store(int64(e.Int(key)))
+	})
+	return p
+}
+
+// BindAtomicBool 返回一个与 key 绑定的布尔原子值，规则同 BindAtomicString
+func (e *environ) BindAtomicBool(key string) *atomic.Bool {
+	p := new(atomic.Bool)
+	e.observe(key, p, func() {
+		p.Store(e.Bool(key))
+	})
+	return p
+}
+
+// Unbind 解除 holder（BindAtomicString 等方法返回的原子值）的绑定，之后它保持最后一次的值，
+// holder 未绑定时不做任何处理
+func (e *environ) Unbind(holder any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, list := range e.observers {
+		kept := make([]observer, 0, len(list))
+		for _, o := range list {
+			if o.holder != holder {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) == 0 {
+			delete(e.observers, key)
+		} else {
+			e.observers[key] = kept
+		}
+	}
+}
+
+// observer 关注某个键的函数，holder 是其更新的原子值，用于 Unbind
+type observer struct {
+	holder any
+	fn     func()
+}
+
+// observe 注册在 key 的数据发生变化时调用的函数，注册时会立即调用一次
+func (e *environ) observe(key string, holder any, fn func()) {
+	e.mu.Lock()
+	if e.observers == nil {
+		e.observers = make(map[string][]observer)
+	}
+	e.observers[key] = append(e.observers[key], observer{holder: holder, fn: fn})
+	e.mu.Unlock()
+	fn()
+}
+
+// notify 通知关注 keys 的函数，调用方不能持有锁
+func (e *environ) notify(keys ...string) {
+	e.mu.RLock()
+	var fns []func()
+	for _, key := range keys {
+		for _, o := range e.observers[key] {
+			fns = append(fns, o.fn)
+		}
+	}
+	e.mu.RUnlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// yamlValue 将 YAML 节点转换为 flatten 可以处理的值，标量使用原始文本，
+// 别名会被展开，合并键（`<<`）中的键不会覆盖映射中显式定义的键
+func yamlValue(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return yamlValue(n.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		var merged []map[string]any
+		for j := 0; j+1 < len(n.Content); j += 2 {
+			if n.Content[j].Tag == "!!merge" {
+				if v, ok := yamlValue(n.Content[j+1]).(map[string]any); ok {
+					merged = append(merged, v)
+				}
+				continue
+			}
+			m[n.Content[j].Value] = yamlValue(n.Content[j+1])
+		}
+		for _, v := range merged {
+			for name, item := range v {
+				if _, ok := m[name]; !ok {
+					m[name] = item
+				}
+			}
+		}
+		return m
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(n.Content))
+		for _, item := range n.Content {
+			items = append(items, yamlValue(item))
+		}
+		return items
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return nil
+		}
+		return n.Value
+	}
+	return nil
+}
+
+// flatten 将 JSON 或 YAML 值展开到 data 中
+func flatten(data map[string]string, key string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, item := range v {
+			flatten(data, joinKey(key, name), item)
+		}
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				for j, item := range v {
+					flatten(data, joinKey(key, strconv.Itoa(j)), item)
+				}
+				return
+			}
+			parts = append(parts, scalar(item))
+		}
+		data[key] = strings.Join(parts, ",")
+	default:
+		data[key] = scalar(v)
+	}
+}
+
+func joinKey(prefix, name string) string {
+	name = strings.ToUpper(name)
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
 }
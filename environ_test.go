@@ -0,0 +1,834 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestEnviron 返回一个只包含 data 的实例，不会读取系统环境变量
+func newTestEnviron(data map[string]string) *environ {
+	e := New().(*environ)
+	e.Save(data)
+	return e
+}
+
+func TestWith(t *testing.T) {
+	e := newTestEnviron(map[string]string{"A": "1", "B": "2"})
+	e.With(map[string]string{"A": "10", "C": "30"}, func() {
+		if got := e.String("A"); got != "10" {
+			t.Errorf("inside With: A = %q, want 10", got)
+		}
+		if got := e.String("C"); got != "30" {
+			t.Errorf("inside With: C = %q, want 30", got)
+		}
+	})
+	assertRestored := func() {
+		t.Helper()
+		if got := e.String("A"); got != "1" {
+			t.Errorf("after With: A = %q, want 1", got)
+		}
+		if e.Exists("C") {
+			t.Error("after With: C should have been removed")
+		}
+		if got := e.String("B"); got != "2" {
+			t.Errorf("after With: B = %q, want 2", got)
+		}
+	}
+	assertRestored()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic inside With was swallowed")
+			}
+		}()
+		e.With(map[string]string{"A": "10", "C": "30"}, func() {
+			panic("boom")
+		})
+	}()
+	assertRestored()
+}
+
+// writeFile 在临时目录中写入文件并返回其路径
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// assertValues 断言 e 中的键值与 want 一致，want 中值为空字符串的键必须不存在
+func assertValues(t *testing.T, e Signer, want map[string]string) {
+	t.Helper()
+	for key, value := range want {
+		got, ok := e.Lookup(key)
+		if value == "" {
+			if e.Exists(key) {
+				t.Errorf("%s = %q, want absent", key, got)
+			}
+			continue
+		}
+		if !ok || got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "config.json", `{
+		"cache": {"book": {"database": 10, "scope": "app:books:"}},
+		"hosts": ["a", "b"],
+		"servers": [{"port": 80}, {"port": 443}],
+		"debug": true,
+		"ratio": 1.50
+	}`)
+	e := newTestEnviron(nil)
+	if err := e.LoadJSON(filename); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{
+		"CACHE_BOOK_DATABASE": "10",
+		"CACHE_BOOK_SCOPE":    "app:books:",
+		"HOSTS":               "a,b",
+		"SERVERS_0_PORT":      "80",
+		"SERVERS_1_PORT":      "443",
+		"DEBUG":               "true",
+		"RATIO":               "1.50",
+	})
+	if err := e.LoadJSON(writeFile(t, t.TempDir(), "bad.json", `{`)); err == nil {
+		t.Error("LoadJSON accepted malformed JSON")
+	}
+}
+
+func TestLoadYAML(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "config.yaml", `
+base: &base
+  driver: redis
+  database: 1
+cache:
+  book:
+    <<: *base
+    database: 10
+    scope: "app:books:"
+hosts: [a, b]
+servers:
+  - port: 80
+  - port: 443
+version: 1.10
+empty: ~
+`)
+	e := newTestEnviron(nil)
+	if err := e.LoadYAML(filename); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{
+		"CACHE_BOOK_DATABASE": "10",
+		"CACHE_BOOK_DRIVER":   "redis",
+		"CACHE_BOOK_SCOPE":    "app:books:",
+		"BASE_DATABASE":       "1",
+		"HOSTS":               "a,b",
+		"SERVERS_0_PORT":      "80",
+		"SERVERS_1_PORT":      "443",
+		"VERSION":             "1.10",
+	})
+	if value, ok := e.values["EMPTY"]; !ok || value != "" {
+		t.Errorf("EMPTY = %q, %v, want stored as empty", value, ok)
+	}
+
+	dir := t.TempDir()
+	if err := e.LoadYAML(writeFile(t, dir, "list.yaml", "- a\n- b\n")); err == nil {
+		t.Error("LoadYAML accepted a top-level sequence")
+	}
+	if err := e.LoadYAML(writeFile(t, dir, "bad.yaml", "a: [\n")); err == nil {
+		t.Error("LoadYAML accepted malformed YAML")
+	}
+	if err := e.LoadYAML(writeFile(t, dir, "empty.yaml", "")); err != nil {
+		t.Errorf("LoadYAML(empty) = %v", err)
+	}
+}
+
+func TestSetReadAuditor(t *testing.T) {
+	e := newTestEnviron(map[string]string{"PORT": "8080", "EMPTY": ""})
+	type read struct {
+		key   string
+		found bool
+	}
+	var reads []read
+	e.SetReadAuditor(func(key string, found bool) {
+		reads = append(reads, read{key, found})
+	})
+	e.String("PORT")
+	e.Int("MISSING")
+	e.Lookup("EMPTY")
+	want := []read{{"PORT", true}, {"MISSING", false}, {"EMPTY", false}}
+	if !reflect.DeepEqual(reads, want) {
+		t.Errorf("audited reads = %v, want %v", reads, want)
+	}
+
+	e.SetReadAuditor(nil)
+	e.String("PORT")
+	if len(reads) != len(want) {
+		t.Error("auditor still called after being removed")
+	}
+}
+
+func TestRegisterLazyFile(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), ".env.extra", "EXTRA=1\nPRESENT=lazy\n")
+	e := newTestEnviron(map[string]string{"PRESENT": "eager"})
+	e.RegisterLazyFile(filename)
+
+	if got := e.String("PRESENT"); got != "eager" {
+		t.Errorf("PRESENT = %q, want eager", got)
+	}
+	if _, ok := e.values["EXTRA"]; ok || len(e.lazy) != 1 {
+		t.Fatal("lazy file was read before any lookup missed")
+	}
+
+	if got := e.String("EXTRA"); got != "1" {
+		t.Errorf("EXTRA = %q after a miss, want 1", got)
+	}
+	if got := e.String("PRESENT"); got != "eager" {
+		t.Errorf("PRESENT = %q, lazy files must not override existing keys", got)
+	}
+
+	// 文件只会读取一次，之后的读取使用缓存的数据
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.String("EXTRA"); got != "1" || len(e.lazy) != 0 {
+		t.Errorf("EXTRA = %q after the file was removed, want cached 1", got)
+	}
+	if e.Exists("STILL_MISSING") {
+		t.Error("STILL_MISSING should not exist")
+	}
+}
+
+func TestChangesSince(t *testing.T) {
+	e := newTestEnviron(nil)
+	e.Set("KEEP", "1")
+	e.Set("CHANGE", "1")
+	e.Set("REMOVE", "1")
+	snapshot := e.Where(func(string, string) bool { return true })
+
+	e.Set("CHANGE", "2")
+	e.Unset("REMOVE")
+	e.Set("ADD_B", "1")
+	e.Set("ADD_A", "1")
+
+	added, removed, changed := e.ChangesSince(snapshot)
+	if want := []string{"ADD_B", "ADD_A"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %q, want %q", added, want)
+	}
+	if want := []string{"REMOVE"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %q, want %q", removed, want)
+	}
+	if want := []string{"CHANGE"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %q, want %q", changed, want)
+	}
+}
+
+func TestLoadBOMAndCRLF(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), ".env", "\xef\xbb\xbfNAME=app\r\nPORT=8080\r\nQUOTED=\"a b\"\r\n")
+	e := newTestEnviron(nil)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"NAME": "app", "PORT": "8080", "QUOTED": "a b"}
+	if got := e.Where(func(string, string) bool { return true }); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %q, want %q", got, want)
+	}
+}
+
+func TestBindAtomic(t *testing.T) {
+	e := newTestEnviron(map[string]string{"NAME": "a", "PORT": "80", "DEBUG": "false"})
+	name := e.BindAtomicString("NAME")
+	port := e.BindAtomicInt("PORT")
+	debug := e.BindAtomicBool("DEBUG")
+	if *name.Load() != "a" || port.Load() != 80 || debug.Load() {
+		t.Fatalf("initial = %q %d %v", *name.Load(), port.Load(), debug.Load())
+	}
+
+	e.Set("NAME", "b")
+	e.Set("PORT", "8080")
+	e.Set("DEBUG", "true")
+	if *name.Load() != "b" || port.Load() != 8080 || !debug.Load() {
+		t.Errorf("after Set = %q %d %v", *name.Load(), port.Load(), debug.Load())
+	}
+
+	e.Unbind(name)
+	e.Set("NAME", "c")
+	if *name.Load() != "b" {
+		t.Errorf("NAME = %q after Unbind, want b", *name.Load())
+	}
+	if _, ok := e.observers["NAME"]; ok {
+		t.Error("Unbind left the observer registered")
+	}
+}
+
+func TestBindAtomicLazyFile(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), ".env.extra", "EXTRA=lazy\n")
+	e := newTestEnviron(map[string]string{"PRESENT": "1"})
+	extra := e.BindAtomicString("EXTRA")
+	if *extra.Load() != "" {
+		t.Fatalf("EXTRA = %q before loading, want empty", *extra.Load())
+	}
+
+	// 其它键未命中时加载延迟文件，绑定的值也需要更新
+	e.RegisterLazyFile(filename)
+	e.String("MISSING")
+	if got := *extra.Load(); got != "lazy" {
+		t.Errorf("EXTRA = %q after the lazy load, want lazy", got)
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	e := newTestEnviron(map[string]string{"SECRET": "s", "NAME": "n", "REF": "${SECRET}"})
+	snapshot := map[string]string{"SECRET": "s", "NAME": "n", "REF": "${SECRET}"}
+	e.SetBlocklist("SECRET")
+
+	if e.Exists("SECRET") || e.String("SECRET") != "" {
+		t.Error("SECRET is visible through Exists or String")
+	}
+	for _, pair := range e.Pairs() {
+		if pair.Key == "SECRET" {
+			t.Error("Pairs contains SECRET")
+		}
+	}
+	if added, removed, changed := e.ChangesSince(snapshot); len(added) != 0 || !reflect.DeepEqual(removed, []string{"SECRET"}) || len(changed) != 0 {
+		t.Errorf("ChangesSince = %v %v %v, want SECRET removed", added, removed, changed)
+	}
+	if got := e.CheckReferences(); !reflect.DeepEqual(got, []string{"SECRET"}) {
+		t.Errorf("CheckReferences = %v, want [SECRET]", got)
+	}
+
+	filename := writeFile(t, t.TempDir(), ".env", "SECRET=s2\nNAME=n2\n")
+	changed, err := e.Reload(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"NAME": "n2"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Reload changed = %v, want %v", changed, want)
+	}
+
+	src := newTestEnviron(map[string]string{"SECRET": "s3", "NAME": "n3"})
+	if got, want := e.swap(src), map[string]string{"NAME": "n3", "REF": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("swap changed = %v, want %v", got, want)
+	}
+	if e.String("SECRET") != "" {
+		t.Error("SECRET became visible after swap")
+	}
+}
+
+func TestReloadFilesSkipsBlocked(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), ".env", "SECRET=a\nNAME=a\n")
+	e := New().(*environ)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	e.SetBlocklist("SECRET")
+	writeFile(t, "", filename, "SECRET=b\nNAME=b\n")
+	changed, err := e.reloadFiles(e.files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"NAME": "b"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("reloadFiles changed = %v, want %v", changed, want)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, ".env", "A=1\nB=2\n")
+	local := writeFile(t, dir, ".env.local", "C=3\n")
+	e := New().(*environ)
+	if err := e.Load(base, local); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, "", base, "A=1\nB=20\nD=4\n")
+	changed, err := e.Reload(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"B": "20", "D": "4"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Reload changed = %v, want %v", changed, want)
+	}
+	assertValues(t, e, map[string]string{"A": "1", "B": "20", "C": "3", "D": "4"})
+
+	if changed, err := e.Reload(base); err != nil || len(changed) != 0 {
+		t.Errorf("Reload of an unchanged file = %v, %v, want no changes", changed, err)
+	}
+	if _, err := e.Reload(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Reload of a missing file should fail")
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	err := New().Load(dir)
+	if want := `env: "` + dir + `" is a directory`; err == nil || err.Error() != want {
+		t.Errorf("Load(dir) error = %v, want %s", err, want)
+	}
+}
+
+func TestResetKeyToOS(t *testing.T) {
+	t.Setenv("ENV_TEST_RESET", "os")
+	e := New().(*environ)
+	e.LoadOS()
+	e.Set("ENV_TEST_RESET", "override")
+	e.Set("ENV_TEST_ADDED", "override")
+	// 快照之后对系统环境变量的修改不会影响恢复的值
+	t.Setenv("ENV_TEST_RESET", "changed")
+
+	e.ResetKeyToOS("ENV_TEST_RESET")
+	e.ResetKeyToOS("ENV_TEST_ADDED")
+	assertValues(t, e, map[string]string{"ENV_TEST_RESET": "os", "ENV_TEST_ADDED": ""})
+}
+
+func TestPairs(t *testing.T) {
+	e := New().(*environ)
+	e.Set("B", "2")
+	e.Set("A", "1")
+	e.Set("C", "3")
+	e.Set("B", "20")
+
+	pairs := e.Pairs()
+	want := []Pair{{"B", "20"}, {"A", "1"}, {"C", "3"}}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("Pairs() = %v, want %v", pairs, want)
+	}
+
+	pairs[0].Value = "changed"
+	e.Set("D", "4")
+	if got := e.String("B"); got != "20" {
+		t.Errorf("B = %q after modifying the returned slice, want 20", got)
+	}
+	if !reflect.DeepEqual(pairs[:3], []Pair{{"B", "changed"}, {"A", "1"}, {"C", "3"}}) || len(pairs) != 3 {
+		t.Errorf("returned slice changed after a write: %v", pairs)
+	}
+}
+
+func TestRegisterScheme(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"DB_PASSWORD": "secret://vault/db",
+		"BROKEN":      "secret://vault/missing",
+		"HOMEPAGE":    "https://example.com",
+		"PLAIN":       "value",
+	})
+	calls := map[string]int{}
+	e.RegisterScheme("secret", func(ref string) (string, error) {
+		calls[ref]++
+		if ref == "vault/missing" {
+			return "", os.ErrNotExist
+		}
+		return "s3cr3t:" + ref, nil
+	})
+
+	for n := 0; n < 2; n++ {
+		if got := e.String("DB_PASSWORD"); got != "s3cr3t:vault/db" {
+			t.Errorf("DB_PASSWORD = %q, want the resolved secret", got)
+		}
+	}
+	if calls["vault/db"] != 1 {
+		t.Errorf("resolver was called %d times, want the result to be cached", calls["vault/db"])
+	}
+	if got := e.String("BROKEN", "fallback"); got != "fallback" {
+		t.Errorf("BROKEN = %q, want fallback when the resolver fails", got)
+	}
+	if got := e.String("HOMEPAGE"); got != "https://example.com" {
+		t.Errorf("HOMEPAGE = %q, unregistered schemes must pass through", got)
+	}
+	if got := e.String("PLAIN"); got != "value" {
+		t.Errorf("PLAIN = %q, want value", got)
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_REF", "os")
+	t.Setenv("ENV_TEST_OS_EMPTY", "")
+	dir := t.TempDir()
+	filename := writeFile(t, dir, ".env", "HOST=example.com\n"+
+		"OS_EMPTY=[${ENV_TEST_OS_EMPTY}]\n"+
+		"BLANK=\n"+
+		"USES_BLANK=[${BLANK}]\n"+
+		"URL=${NOPE}/path\n"+
+		"API=https://${HOST}/api\n"+
+		"OS=${ENV_TEST_OS_REF}\n"+
+		"BARE=$MISSING_TOO\n"+
+		"FORWARD=${LATER}\n"+
+		"LATER=1\n"+
+		"QUOTED='${LITERAL}'\n"+
+		"ESCAPED=$${DOLLAR}\n")
+	e := New().(*environ)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	e.Set("MANUAL", "${UNSET}")
+
+	if got := e.String("URL"); got != "/path" {
+		t.Errorf("URL = %q, want /path", got)
+	}
+	// 已定义但值为空的键可以解析，不会被报告
+	assertValues(t, e, map[string]string{"OS_EMPTY": "[]", "USES_BLANK": "[]"})
+	// 同一文件中键的写入顺序不固定，因此排序后比较；
+	// 单引号与 `$$` 保留的字面量引用会在读取时展开（见 SetExpand），同样需要报告
+	got := e.CheckReferences()
+	sort.Strings(got)
+	want := []string{"DOLLAR", "LATER", "LITERAL", "MISSING_TOO", "NOPE", "UNSET"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckReferences() = %v, want %v", got, want)
+	}
+
+	// 重新写入的键不再报告加载时的引用
+	e.Set("URL", "http://localhost/path")
+	e.Set("FORWARD", "${LATER}")
+	e.Set("QUOTED", "x")
+	e.Set("ESCAPED", "x")
+	got = e.CheckReferences()
+	sort.Strings(got)
+	if want := []string{"MISSING_TOO", "UNSET"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckReferences() = %v after rewriting keys, want %v", got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	e := New().(*environ)
+	e.Set("APP_NAME", "demo")
+	e.Set("DB_PASSWORD", "hunter2")
+	e.Set("PORT", "8080")
+	e.Set("api_token", "abc")
+
+	want := "APP_NAME=demo\nDB_PASSWORD=******\nPORT=8080\napi_token=******"
+	for _, format := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(format, e); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", format, got, want)
+		}
+	}
+	if got := fmt.Sprint(New()); got != "" {
+		t.Errorf("Sprint(New()) = %q, want empty", got)
+	}
+}
+
+// benchEnviron 返回包含 n 个键的实例，键名为 KEY_0 至 KEY_{n-1}
+func benchEnviron(n int) (*environ, []string) {
+	e := New().(*environ)
+	keys := make([]string, n)
+	data := make(map[string]string, n)
+	for i := range keys {
+		keys[i] = "KEY_" + strconv.Itoa(i)
+		data[keys[i]] = strconv.Itoa(i)
+	}
+	e.Save(data)
+	return e, keys
+}
+
+func BenchmarkLookup1000(b *testing.B) {
+	e, keys := benchEnviron(1000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		e.Lookup(keys[n%len(keys)])
+	}
+}
+
+func BenchmarkExists1000(b *testing.B) {
+	e, keys := benchEnviron(1000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		e.Exists(keys[n%len(keys)])
+	}
+}
+
+func BenchmarkSet1000(b *testing.B) {
+	e, keys := benchEnviron(1000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		e.Set(keys[n%len(keys)], "value")
+	}
+}
+
+func BenchmarkMap1000(b *testing.B) {
+	e, _ := benchEnviron(1000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		e.Map("")
+	}
+}
+
+func TestIterConcurrentSave(t *testing.T) {
+	e := newTestEnviron(map[string]string{"A": "0"})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 1000; n++ {
+			e.Save(map[string]string{"KEY_" + strconv.Itoa(n): "x", "A": strconv.Itoa(n)})
+			if n%10 == 0 {
+				e.Unset("KEY_" + strconv.Itoa(n-5))
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			// A 与 1000 个 KEY_n，其中 99 个已被移除
+			if got, want := len(e.Map("")), 1+1000-99; got != want {
+				t.Errorf("Map() has %d keys, want %d", got, want)
+			}
+			return
+		default:
+		}
+		for key, value := range e.Map("") {
+			if value == "" {
+				t.Fatalf("Map() returned an empty value for %s", key)
+			}
+		}
+		e.Where(func(key, value string) bool { return key == "A" })
+	}
+}
+
+func TestLoadInterpolation(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_HOST", "os.local")
+	dir := t.TempDir()
+	e := New().(*environ)
+	e.Set("PORT", "8080")
+	base := writeFile(t, dir, ".env", "HOST=${ENV_TEST_OS_HOST}\n"+
+		"BASE_URL=http://${HOST}:$PORT\n"+
+		"API_URL=${BASE_URL}/api\n"+
+		"PRICE=$$5\n"+
+		"LITERAL='${HOST}'\n"+
+		"LOOP_A=${LOOP_B}\n"+
+		"LOOP_B=${LOOP_A}\n"+
+		"UNSET=[${NOPE}]\n")
+	local := writeFile(t, dir, ".env.local", "HOST=local\nURL=${HOST}/${API_URL}\n")
+	if err := e.Load(base, local); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{
+		"HOST":     "local",
+		"BASE_URL": "http://os.local:8080",
+		"API_URL":  "http://os.local:8080/api",
+		"URL":      "local/http://os.local:8080/api",
+		"PRICE":    "$5",
+		"LITERAL":  "${HOST}",
+		"UNSET":    "[]",
+	})
+	// 循环引用只展开一次：LOOP_A 引用的 LOOP_B 此时尚未定义，LOOP_B 得到 LOOP_A 的空值
+	if a, b := e.values["LOOP_A"], e.values["LOOP_B"]; a != "" || b != "" {
+		t.Errorf("LOOP_A = %q, LOOP_B = %q, want both empty", a, b)
+	}
+}
+
+func TestLoadNoOverride(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_VALUE", "os")
+	dir := t.TempDir()
+	first := writeFile(t, dir, ".env", "ENV_TEST_OS_VALUE=file\nA=first\n")
+	second := writeFile(t, dir, ".env.local", "A=second\nB=second\n")
+
+	e := New().(*environ)
+	e.LoadOS()
+	if err := e.LoadNoOverride(first, second); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"ENV_TEST_OS_VALUE": "os", "A": "first", "B": "second"})
+
+	if err := e.Load(first, second); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"ENV_TEST_OS_VALUE": "file", "A": "second", "B": "second"})
+}
+
+func TestLoadReader(t *testing.T) {
+	e := New().(*environ)
+	e.Set("HOST", "example.com")
+	e.Set("PORT", "80")
+	if err := e.LoadReader(strings.NewReader("PORT=8080\nURL=http://${HOST}:${PORT}\n# comment\nQUOTED=\"a b\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"HOST": "example.com", "PORT": "8080", "URL": "http://example.com:8080", "QUOTED": "a b"})
+	if err := e.LoadReader(strings.NewReader("BROKEN=\"unterminated\n")); err == nil {
+		t.Error("LoadReader() with invalid content should fail")
+	}
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	e := newTestEnviron(map[string]string{"CACHE_BOOK_DATABASE": "10", "cache.mixed": "raw"})
+	if e.Exists("cache.book.database") {
+		t.Error("dotted keys must not resolve before normalization is enabled")
+	}
+	e.SetNormalizeKeys(true)
+	if got := e.Int("cache.book.database"); got != 10 {
+		t.Errorf("Int(cache.book.database) = %d, want 10", got)
+	}
+	if got := e.String("Cache.Book_Database"); got != "10" {
+		t.Errorf("String(Cache.Book_Database) = %q, want 10", got)
+	}
+	if got := e.String("cache.mixed"); got != "raw" {
+		t.Errorf("String(cache.mixed) = %q, exact keys must still win", got)
+	}
+	if got := e.Signed("cache", "book").String("database"); got != "10" {
+		t.Errorf("Signed(cache, book).String(database) = %q, want 10", got)
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := writeFile(t, dir, ".env", "PLAIN=value\n"+
+		"URL=http://example.com/a?b=c&d=e\n"+
+		"SPACES=\"hello world\"\n"+
+		"QUOTES='say \"hi\"'\n"+
+		"NEWLINE=\"line1\\nline2\"\n"+
+		"DOLLAR='$HOME and ${HOME}'\n"+
+		"HASH=\"a # not a comment\"\n"+
+		"SPECIAL=\"back`tick! and 'single'\"\n"+
+		"EMPTY=\n"+
+		"dotted.key=1\n")
+	e := New().(*environ)
+	if err := e.Load(src); err != nil {
+		t.Fatal(err)
+	}
+	e.Set("ADDED", "tab\there")
+	e.Set("WIN", `C:\dir\`)
+	e.Set("WIN_SPACES", `C:\Program Files\it's "x"\`)
+
+	dumped := filepath.Join(dir, ".env.dump")
+	if err := e.WriteFile(dumped); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := New().(*environ)
+	if err := reloaded.Load(dumped); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reloaded.Map(""), e.Map(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded = %q, want %q", got, want)
+	}
+	if info, err := os.Stat(dumped); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("dumped file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	// Dump 按写入顺序输出
+	out, err := e.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		key, _, _ := strings.Cut(line, "=")
+		keys = append(keys, key)
+	}
+	var want []string
+	for _, pair := range e.Pairs() {
+		want = append(want, pair.Key)
+	}
+	if len(keys) != len(want) || !reflect.DeepEqual(keys, want) {
+		t.Errorf("Dump() keys = %q, want %q", keys, want)
+	}
+}
+
+func TestDumpErrors(t *testing.T) {
+	for key, value := range map[string]string{
+		"BAD KEY":          "1",
+		"TRAILING_DOLLAR":  `$HOME\`,
+		"TRAILING_NEWLINE": "a\nb\\",
+		"TRAILING_SPACE":   ` C:\dir\`,
+		"QUOTES":           "it's \"x\"",
+	} {
+		e := newTestEnviron(map[string]string{key: value})
+		if _, err := e.Dump(); err == nil {
+			t.Errorf("Dump() with %q=%q should fail", key, value)
+		}
+	}
+}
+
+func TestLoadInterpolationQuotedValues(t *testing.T) {
+	e := New().(*environ)
+	e.Set("GREETING", `say "hi"`)
+	e.Set("PATH_LIKE", `C:\dir\`)
+	e.Set("DOLLARS", `cost $5 "each"`)
+	if err := e.LoadReader(strings.NewReader("A=[${GREETING}]\nB=[${PATH_LIKE}]\nC=[${DOLLARS}]\n")); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"A": `[say "hi"]`, "B": `[C:\dir\]`, "C": `[cost $5 "each"]`})
+	if got := e.CheckReferences(); len(got) != 0 {
+		t.Errorf("CheckReferences() = %v, want none", got)
+	}
+}
+
+func TestLoadInterpolationNames(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_lower", "os")
+	e := New().(*environ)
+	e.Set("db_host", "localhost")
+	src := "db_port=5432\n" +
+		"DSN=${db_host}:$db_port\n" +
+		"OS=${ENV_TEST_OS_lower}\n" +
+		"ESCAPED=\"\\$db_host\"\n" +
+		"MULTI='first $db_host\nsecond ${db_port}'\n" +
+		"BARE=100$ and $\n"
+	if err := e.LoadReader(strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{
+		"DSN":     "localhost:5432",
+		"OS":      "os",
+		"ESCAPED": "$db_host",
+		"MULTI":   "first $db_host\nsecond ${db_port}",
+		"BARE":    "100$ and $",
+	})
+}
+
+func TestLoadInterpolationBlocked(t *testing.T) {
+	t.Setenv("ENV_TEST_OS_SECRET", "hunter2")
+	e := New().(*environ)
+	e.Set("LOADED_SECRET", "s3cret")
+	e.SetBlocklist("ENV_TEST_OS_SECRET", "LOADED_SECRET", "FILE_SECRET")
+	src := "FILE_SECRET=top\n" +
+		"LEAK_OS=[${ENV_TEST_OS_SECRET}]\n" +
+		"LEAK_LOADED=[${LOADED_SECRET}]\n" +
+		"LEAK_FILE=[$FILE_SECRET]\n"
+	if err := e.LoadReader(strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, e, map[string]string{"LEAK_OS": "[]", "LEAK_LOADED": "[]", "LEAK_FILE": "[]"})
+}
+
+func TestExportToOS(t *testing.T) {
+	// t.Setenv 会在测试结束后恢复（或移除）这些环境变量
+	t.Setenv("ENV_TEST_EXPORT_NEW", "")
+	os.Unsetenv("ENV_TEST_EXPORT_NEW")
+	t.Setenv("ENV_TEST_EXPORT_EXISTING", "os")
+	t.Setenv("ENV_TEST_EXPORT_BLOCKED", "")
+	os.Unsetenv("ENV_TEST_EXPORT_BLOCKED")
+
+	e := newTestEnviron(map[string]string{
+		"ENV_TEST_EXPORT_NEW":      "new",
+		"ENV_TEST_EXPORT_EXISTING": "file",
+		"ENV_TEST_EXPORT_BLOCKED":  "secret",
+	})
+	e.SetBlocklist("ENV_TEST_EXPORT_BLOCKED")
+
+	if err := e.ExportToOS(false); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("ENV_TEST_EXPORT_NEW"); got != "new" {
+		t.Errorf("ENV_TEST_EXPORT_NEW = %q, want new", got)
+	}
+	if got := os.Getenv("ENV_TEST_EXPORT_EXISTING"); got != "os" {
+		t.Errorf("ENV_TEST_EXPORT_EXISTING = %q, existing variables must be kept", got)
+	}
+	if _, ok := os.LookupEnv("ENV_TEST_EXPORT_BLOCKED"); ok {
+		t.Error("blocked keys must not be exported")
+	}
+
+	if err := e.ExportToOS(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("ENV_TEST_EXPORT_EXISTING"); got != "file" {
+		t.Errorf("ENV_TEST_EXPORT_EXISTING = %q, want file with overwrite", got)
+	}
+}
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"zestack.dev/cast"
+)
+
+// Get 将全局数据中指定键的值转换为类型 T，数据不存在或值为空时返回默认值（或零值），
+// 转换失败时返回错误，适合读取 int16、自定义字符串类型等没有专门方法的类型
+func Get[T any](key string, fallback ...T) (T, error) {
+	var zero T
+	value, ok := Default().Lookup(key)
+	if !ok {
+		for _, v := range fallback {
+			return v, nil
+		}
+		return zero, nil
+	}
+	v, err := cast.FromType(value, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return zero, fmt.Errorf("env: cannot convert %q to %T; err: %v", key, zero, err)
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("env: cannot convert %q to %T", key, zero)
+	}
+	return t, nil
+}
+
+// FlagSet 将全局数据中以英文逗号分割的名称按 names 映射为标志位并合并，如
+//
+//	PERMISSIONS=read,write
+//
+//	perm, err := env.FlagSet("PERMISSIONS", map[string]Perm{"read": Read, "write": Write})
+//
+// 数据不存在时返回零值，存在未知的名称时返回错误
+func FlagSet[T ~int](key string, names map[string]T) (T, error) {
+	var result T
+	value, ok := Default().Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		flag, ok := names[name]
+		if !ok {
+			return 0, fmt.Errorf("env: unknown flag %q in %q", name, key)
+		}
+		result |= flag
+	}
+	return result, nil
+}
+
+// GetJSON 将查询器 s（为 nil 时使用全局数据）中指定键的值作为 JSON 解码为类型 T，如
+//
+//	cfg, err := env.GetJSON[Config](nil, "CONFIG")
+//
+// 数据不存在时返回默认值（或零值），解码失败时返回默认值（或零值）以及错误
+func GetJSON[T any](s Signer, key string, fallback ...T) (T, error) {
+	var result T
+	for _, v := range fallback {
+		result = v
+		break
+	}
+	if s == nil {
+		s = Default()
+	}
+	value, ok := s.Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	var t T
+	if err := json.Unmarshal([]byte(value), &t); err != nil {
+		return result, fmt.Errorf("env: cannot decode JSON %q: %w", key, err)
+	}
+	return t, nil
+}
+
+// Structs 将值解析为结构体切片，items 之间使用 itemSep 分割，每个 item 中的字段
+// 使用 fieldSep 分割，字段的键与值使用 kvSep 分割，字段按照结构体的 `env` 标签填充，如
+//
+//	ROUTES=path=/a;handler=x|path=/b;handler=y
+//
+//	type Route struct {
+//		Path    string `env:"path"`
+//		Handler string `env:"handler"`
+//	}
+//
+//	routes, err := env.Structs[Route]("ROUTES", "|", ";", "=")
+func Structs[T any](key string, itemSep, fieldSep, kvSep string) ([]T, error) {
+	value, ok := Default().Lookup(key)
+	if !ok {
+		return nil, nil
+	}
+	var result []T
+	for _, item := range strings.Split(value, itemSep) {
+		data := make(map[string]string)
+		for _, field := range strings.Split(item, fieldSep) {
+			if strings.TrimSpace(field) == "" {
+				continue
+			}
+			name, val, found := strings.Cut(field, kvSep)
+			if !found {
+				return nil, fmt.Errorf("env: invalid field %q in %q", field, key)
+			}
+			data[strings.TrimSpace(name)] = strings.TrimSpace(val)
+		}
+		e := New()
+		e.Save(data)
+		var t T
+		if err := e.Fill(&t); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStructs(t *testing.T) {
+	type Route struct {
+		Path    string `env:"path"`
+		Handler string `env:"handler"`
+		Weight  int    `env:"weight" default:"1"`
+	}
+	useDefault(t, map[string]string{
+		"ROUTES":  "path=/a;handler=x|path=/b;handler=y;weight=3",
+		"BAD":     "path=/a;handler",
+		"BAD_INT": "path=/a;weight=heavy",
+	})
+
+	got, err := Structs[Route]("ROUTES", "|", ";", "=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Route{{"/a", "x", 1}, {"/b", "y", 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Structs(ROUTES) = %+v, want %+v", got, want)
+	}
+
+	for _, key := range []string{"BAD", "BAD_INT"} {
+		if _, err := Structs[Route](key, "|", ";", "="); err == nil {
+			t.Errorf("Structs(%s) should fail", key)
+		}
+	}
+	if got, err := Structs[Route]("MISSING", "|", ";", "="); got != nil || err != nil {
+		t.Errorf("Structs(MISSING) = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	type Level string
+	useDefault(t, map[string]string{"SHARDS": "12", "LEVEL": "debug", "TIMEOUT": "5s", "BAD": "many"})
+
+	if got, err := Get[int16]("SHARDS"); err != nil || got != 12 {
+		t.Errorf("Get[int16](SHARDS) = %v, %v, want 12", got, err)
+	}
+	if got, err := Get[Level]("LEVEL"); err != nil || got != "debug" {
+		t.Errorf("Get[Level](LEVEL) = %v, %v, want debug", got, err)
+	}
+	if got, err := Get[time.Duration]("TIMEOUT"); err != nil || got != 5*time.Second {
+		t.Errorf("Get[time.Duration](TIMEOUT) = %v, %v, want 5s", got, err)
+	}
+	if got, err := Get[int16]("MISSING", 3); err != nil || got != 3 {
+		t.Errorf("Get[int16](MISSING, 3) = %v, %v, want fallback 3", got, err)
+	}
+	if got, err := Get[int16]("MISSING"); err != nil || got != 0 {
+		t.Errorf("Get[int16](MISSING) = %v, %v, want 0", got, err)
+	}
+	if got, err := Get[int16]("BAD", 3); err == nil || got != 0 {
+		t.Errorf("Get[int16](BAD) = %v, %v, want an error", got, err)
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	type Config struct {
+		Name  string `json:"name"`
+		Limit int    `json:"limit"`
+	}
+	e := newTestEnviron(map[string]string{
+		"CONFIG":  `{"name":"demo","limit":3}`,
+		"HOSTS":   `["a","b"]`,
+		"INVALID": `{"name":`,
+	})
+	fallback := Config{Name: "fallback"}
+
+	if got, err := GetJSON[Config](e, "CONFIG", fallback); err != nil || got != (Config{"demo", 3}) {
+		t.Errorf("GetJSON(CONFIG) = %+v, %v", got, err)
+	}
+	if got, err := GetJSON[[]string](e, "HOSTS"); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("GetJSON(HOSTS) = %v, %v", got, err)
+	}
+	if got, err := GetJSON[Config](e, "MISSING", fallback); err != nil || got != fallback {
+		t.Errorf("GetJSON(MISSING) = %+v, %v, want fallback", got, err)
+	}
+	if got, err := GetJSON[Config](e, "INVALID", fallback); err == nil || got != fallback {
+		t.Errorf("GetJSON(INVALID) = %+v, %v, want fallback and an error", got, err)
+	}
+
+	// s 为 nil 时使用全局实例
+	useDefault(t, map[string]string{"CONFIG": `{"name":"global"}`})
+	if got, err := GetJSON[Config](nil, "CONFIG"); err != nil || got.Name != "global" {
+		t.Errorf("GetJSON(nil, CONFIG) = %+v, %v", got, err)
+	}
+}
+
+func TestFlagSet(t *testing.T) {
+	type Perm int
+	const (
+		Read Perm = 1 << iota
+		Write
+		Exec
+	)
+	names := map[string]Perm{"read": Read, "write": Write, "exec": Exec}
+	useDefault(t, map[string]string{
+		"PERMS":   "read, exec,,",
+		"UNKNOWN": "read,delete",
+	})
+
+	perm, err := FlagSet("PERMS", names)
+	if err != nil || perm != Read|Exec {
+		t.Errorf("FlagSet(PERMS) = %d, %v, want %d", perm, err, Read|Exec)
+	}
+	perm, err = FlagSet("MISSING", names)
+	if err != nil || perm != 0 {
+		t.Errorf("FlagSet(MISSING) = %d, %v, want 0", perm, err)
+	}
+	perm, err = FlagSet("UNKNOWN", names)
+	if err == nil || perm != 0 {
+		t.Errorf("FlagSet(UNKNOWN) = %d, %v, want an error", perm, err)
+	}
+}
@@ -1,11 +1,20 @@
 package env
 
 import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 	"unsafe"
 
@@ -14,20 +23,78 @@ import (
 
 var _ Signer = &inner{}
 
+// Encoding 二进制数据的编码方式
+type Encoding int
+
+const (
+	// EncodingRaw 不编码，直接使用原始值
+	EncodingRaw Encoding = iota
+	// EncodingBase64 标准 base64 编码
+	EncodingBase64
+	// EncodingHex 十六进制编码
+	EncodingHex
+)
+
+// decode 按照编码方式解码数据
+func (enc Encoding) decode(value string) ([]byte, error) {
+	switch enc {
+	case EncodingBase64:
+		return decodeBase64(value)
+	case EncodingHex:
+		return hex.DecodeString(value)
+	default:
+		return []byte(value), nil
+	}
+}
+
+// decodeBase64 使用标准 base64 编码解码，失败时再尝试不带填充的编码
+func decodeBase64(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if raw, rawErr := base64.RawStdEncoding.DecodeString(value); rawErr == nil {
+			return raw, nil
+		}
+	}
+	return data, err
+}
+
 type inner struct {
-	lookup func(key string) (string, bool)
-	exists func(key string) bool
-	iter   func() func() (key string, value string, ok bool)
+	lookup       func(key string) (string, bool)
+	exists       func(key string) bool
+	iter         func() func() (key string, value string, ok bool)
+	qualify      func(key string) string
+	root         func(key string) (string, bool)
+	save         func(data map[string]string)
+	candidates   func(key string) []string
+	withEncoding func(enc Encoding) Signer
+	encoding     Encoding
 }
 
 func (i *inner) Lookup(key string) (string, bool) {
 	return i.lookup(key)
 }
 
+// LookupScopedAndRoot 同时返回当前作用域与根作用域中的数据，根作用域中两者相同
+func (i *inner) LookupScopedAndRoot(key string) (scoped string, scopedOK bool, root string, rootOK bool) {
+	scoped, scopedOK = i.Lookup(key)
+	root, rootOK = i.root(key)
+	return
+}
+
 func (i *inner) Exists(key string) bool {
 	return i.exists(key)
 }
 
+// Save 保存数据，已存在的键会被覆盖
+func (i *inner) Save(data map[string]string) {
+	i.save(data)
+}
+
+// WithEncoding 返回一个相同作用域的查询器，其 Bytes 方法会按照 enc 解码数据
+func (i *inner) WithEncoding(enc Encoding) Signer {
+	return i.withEncoding(enc)
+}
+
 // String 取字符串值
 func (i *inner) String(key string, fallback ...string) string {
 	if value, exists := i.Lookup(key); exists {
@@ -39,10 +106,88 @@ func (i *inner) String(key string, fallback ...string) string {
 	return ""
 }
 
-// Bytes 取二进制值
+// StringPtr 取字符串值的指针，数据不存在时返回 nil，用于区分未设置与空值
+func (i *inner) StringPtr(key string) *string {
+	if value, ok := i.Lookup(key); ok || i.Exists(key) {
+		return &value
+	}
+	return nil
+}
+
+// IntPtr 取整数值的指针，数据不存在或无法解析时返回 nil
+func (i *inner) IntPtr(key string) *int {
+	if value, ok := i.Lookup(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return &n
+		}
+	}
+	return nil
+}
+
+// BoolPtr 取布尔值（规则同 Bool）的指针，数据不存在或无法解析时返回 nil
+func (i *inner) BoolPtr(key string) *bool {
+	if value, ok := i.Lookup(key); ok {
+		if b, err := parseBool(value); err == nil {
+			return &b
+		}
+	}
+	return nil
+}
+
+// StringFunc 取字符串值，数据不存在或值为空时调用 provider 获取，
+// provider 的结果不会被缓存，每次未命中都会重新调用
+func (i *inner) StringFunc(key string, provider func() string) string {
+	if value, exists := i.Lookup(key); exists {
+		return value
+	}
+	return provider()
+}
+
+// Bytes 取二进制值，值会按照查询器的编码方式解码，解码失败时返回默认值
 func (i *inner) Bytes(key string, fallback ...[]byte) []byte {
 	if value, exists := i.Lookup(key); exists {
-		return []byte(value)
+		if bytes, err := i.encoding.decode(value); err == nil {
+			return bytes
+		}
+	}
+	for _, bytes := range fallback {
+		return bytes
+	}
+	return []byte{}
+}
+
+// Base64 将值作为 base64 编码（带或不带填充）解码，数据不存在时返回默认值，解码失败时返回错误
+func (i *inner) Base64(key string, fallback ...[]byte) ([]byte, error) {
+	if value, ok := i.Lookup(key); ok {
+		data, err := decodeBase64(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid base64 value for %q: %w", key, err)
+		}
+		return data, nil
+	}
+	for _, value := range fallback {
+		return value, nil
+	}
+	return nil, nil
+}
+
+// ByteSlice 将值按 `,` 分割并将每个 0-255 的整数作为一个字节返回，
+// 如 `10,20,30`，任意元素无效时返回默认值
+func (i *inner) ByteSlice(key string, fallback ...[]byte) []byte {
+	if value, exists := i.Lookup(key); exists {
+		parts := strings.Split(value, ",")
+		bytes := make([]byte, 0, len(parts))
+		for _, part := range parts {
+			n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+			if err != nil {
+				bytes = nil
+				break
+			}
+			bytes = append(bytes, byte(n))
+		}
+		if bytes != nil {
+			return bytes
+		}
 	}
 	for _, bytes := range fallback {
 		return bytes
@@ -63,14 +208,197 @@ func (i *inner) Int(key string, fallback ...int) int {
 	return 0
 }
 
+// IntE 与 Int 相同，但值存在却无法解析时返回错误（以及默认值），便于区分未设置与设置有误
+func (i *inner) IntE(key string, fallback ...int) (int, error) {
+	var result int
+	for _, value := range fallback {
+		result = value
+		break
+	}
+	val, ok := i.Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return result, fmt.Errorf("env: invalid value %q for %q", val, key)
+	}
+	return n, nil
+}
+
+// Int64 取 64 位整型值，超出范围时返回默认值
+func (i *inner) Int64(key string, fallback ...int64) int64 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return n
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Uint 取无符号整型值，负数或超出范围时返回默认值
+func (i *inner) Uint(key string, fallback ...uint) uint {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseUint(val, 10, strconv.IntSize); err == nil {
+			return uint(n)
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Uint64 取 64 位无符号整型值，负数或超出范围时返回默认值
+func (i *inner) Uint64(key string, fallback ...uint64) uint64 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseUint(val, 10, 64); err == nil {
+			return n
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// maxIntRanges IntRanges 展开后允许的最大元素数量
+const maxIntRanges = 65536
+
+// IntRanges 将值解析为整数列表，元素之间使用 `,` 分割，`a-b` 表示闭区间 [a, b]，
+// 如 `8080,9000-9005`，区间反向或展开后超过 65536 个元素时返回错误，数据不存在时返回空
+func (i *inner) IntRanges(key string) ([]int, error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return nil, nil
+	}
+	var result []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid range %q in %q", part, key)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("env: invalid range %q in %q", part, key)
+			}
+			if end < start {
+				return nil, fmt.Errorf("env: reversed range %q in %q", part, key)
+			}
+		}
+		// 先比较差值再展开，避免 end-start 过大时的整数溢出
+		if end-start >= maxIntRanges-len(result) {
+			return nil, fmt.Errorf("env: ranges in %q expand to more than %d values", key, maxIntRanges)
+		}
+		for n := start; n <= end; n++ {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+// IntArray3 将值按 `,` 分割为 3 个整数，如 `255,128,0`，
+// 数据不存在、元素数量不是 3 或任意元素无效时返回错误
+func (i *inner) IntArray3(key string) ([3]int, error) {
+	var result [3]int
+	value, ok := i.Lookup(key)
+	if !ok {
+		return result, fmt.Errorf("env: missing required variable %q", key)
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != len(result) {
+		return result, fmt.Errorf("env: expected %d values in %q, got %d", len(result), key, len(parts))
+	}
+	for j, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return result, fmt.Errorf("env: invalid value %q in %q", part, key)
+		}
+		result[j] = n
+	}
+	return result, nil
+}
+
+// Float64 取浮点数值，支持科学计数法（如 `1.5e6`）
+func (i *inner) Float64(key string, fallback ...float64) float64 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseFloat(val, 64); err == nil {
+			return n
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Float64E 与 Float64 相同，但值存在却无法解析时返回错误（以及默认值），便于区分未设置与设置有误
+func (i *inner) Float64E(key string, fallback ...float64) (float64, error) {
+	var result float64
+	for _, value := range fallback {
+		result = value
+		break
+	}
+	val, ok := i.Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	n, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return result, fmt.Errorf("env: invalid value %q for %q", val, key)
+	}
+	return n, nil
+}
+
+// Float32 取单精度浮点数值
+func (i *inner) Float32(key string, fallback ...float32) float32 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseFloat(val, 32); err == nil {
+			return float32(n)
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Float64Locale 取浮点数值，解析前会去除千位分隔符 `,`（如 `1,000.50`）
+func (i *inner) Float64Locale(key string, fallback ...float64) float64 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := strconv.ParseFloat(strings.ReplaceAll(val, ",", ""), 64); err == nil {
+			return n
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// SampleRate 取采样率，支持 `0.1` 与 `10%` 两种写法，结果限制在 [0, 1] 之间，`NaN` 视为无效值
+func (i *inner) SampleRate(key string, fallback ...float64) float64 {
+	if val, exists := i.Lookup(key); exists {
+		if n, err := parsePercent(val); err == nil && !math.IsNaN(n) {
+			return math.Max(0, math.Min(1, n))
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Duration 取时长值，签名查询器沿用相同的解析规则
 func (i *inner) Duration(key string, fallback ...time.Duration) time.Duration {
 	if val, ok := i.Lookup(key); ok {
-		n, err := strconv.Atoi(val)
-		if err == nil {
-			return time.Duration(n)
-		}
-		d, err := time.ParseDuration(val)
-		if err == nil {
+		if d, err := parseDuration(val); err == nil {
 			return d
 		}
 	}
@@ -80,9 +408,248 @@ func (i *inner) Duration(key string, fallback ...time.Duration) time.Duration {
 	return 0
 }
 
+// DurationE 与 Duration 相同，但值存在却无法解析时返回错误（以及默认值），便于区分未设置与设置有误
+func (i *inner) DurationE(key string, fallback ...time.Duration) (time.Duration, error) {
+	var result time.Duration
+	for _, value := range fallback {
+		result = value
+		break
+	}
+	val, ok := i.Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	d, err := parseDuration(val)
+	if err != nil {
+		return result, fmt.Errorf("env: invalid value %q for %q", val, key)
+	}
+	return d, nil
+}
+
+// DurationSum 将值按 `,` 分割并逐个解析为时长（规则同 Duration）后求和，如 `30s,500ms`，
+// 数据不存在或任意元素无效时返回 0
+func (i *inner) DurationSum(key string) time.Duration {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return 0
+	}
+	var sum time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := parseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return 0
+		}
+		sum += d
+	}
+	return sum
+}
+
+// maxSchedule Schedule 展开后允许的最大元素数量
+const maxSchedule = 65536
+
+// Schedule 将值解析为重试或退避的时间表，元素之间使用 `,` 分割，
+// 元素后可以使用 ` xN` 表示重复 N 次，如 `1s,2s x3,5s` 等价于 `1s,2s,2s,2s,5s`，
+// 数据不存在、格式有误或展开后超过 65536 个元素时返回空切片
+func (i *inner) Schedule(key string) []time.Duration {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return []time.Duration{}
+	}
+	var schedule []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		times := 1
+		if j := strings.LastIndex(part, " x"); j > -1 {
+			n, err := strconv.Atoi(part[j+2:])
+			if err != nil || n < 1 {
+				return []time.Duration{}
+			}
+			part, times = strings.TrimSpace(part[:j]), n
+		}
+		d, err := parseDuration(part)
+		if err != nil || times > maxSchedule-len(schedule) {
+			return []time.Duration{}
+		}
+		for ; times > 0; times-- {
+			schedule = append(schedule, d)
+		}
+	}
+	return schedule
+}
+
+// Bandwidth 将形如 `10MB/s`、`500KiB/s` 的带宽换算为每秒的字节数，
+// 省略时间单位时视为每秒，省略容量单位时视为字节，数据不存在时返回 0，格式有误或超出 int64 范围时返回错误
+func (i *inner) Bandwidth(key string) (bytesPerSec int64, err error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return 0, nil
+	}
+	size, unit, _ := strings.Cut(value, "/")
+	n, err := parseSize(size)
+	if err != nil {
+		return 0, fmt.Errorf("env: invalid bandwidth %q for %q", value, key)
+	}
+	seconds, err := rateUnit(unit)
+	if err != nil {
+		return 0, fmt.Errorf("env: invalid bandwidth %q for %q", value, key)
+	}
+	// 超出范围的浮点数转换为 int64 的结果是未定义的，需要先检查
+	if n/seconds >= math.MaxInt64 {
+		return 0, fmt.Errorf("env: bandwidth %q for %q overflows int64", value, key)
+	}
+	return int64(n / seconds), nil
+}
+
+// TimeOfDay 将 `HH:MM` 格式的值解析为一天中的时刻，如 `02:00`、`23:59`，
+// 由于 `00:00` 是有效值，数据不存在时同样返回错误
+func (i *inner) TimeOfDay(key string) (hour, minute int, err error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return 0, 0, fmt.Errorf("env: missing required variable %q", key)
+	}
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("env: invalid time of day %q for %q", value, key)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Cron 取 cron 表达式并校验语法，支持 5 个字段（分 时 日 月 周）或
+// 6 个字段（秒 分 时 日 月 周），如 `*/5 * * * *`，数据不存在或格式有误时返回错误
+func (i *inner) Cron(key string) (string, error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("env: missing required variable %q", key)
+	}
+	fields := strings.Fields(value)
+	bounds, names := cronBounds[:], cronNames[:]
+	switch len(fields) {
+	case 5:
+		bounds, names = bounds[1:], names[1:]
+	case 6:
+	default:
+		return "", fmt.Errorf("env: invalid cron expression %q for %q: expected 5 or 6 fields, got %d", value, key, len(fields))
+	}
+	for j, field := range fields {
+		if err := checkCronField(field, bounds[j], names[j]); err != nil {
+			return "", fmt.Errorf("env: invalid cron expression %q for %q: %v", value, key, err)
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// cronBounds cron 表达式各字段（秒 分 时 日 月 周）的取值范围，周日可以写作 0 或 7
+var cronBounds = [6][2]int{{0, 59}, {0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// cronNames 月与周字段可用的英文缩写，按顺序对应从取值范围下限开始的数字
+var cronNames = [6][]string{
+	4: {"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	5: {"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"},
+}
+
+// checkCronField 校验 cron 表达式的单个字段，支持 `*`、`?`、数字、`a-b`、`/n` 以及使用 `,` 分割的列表，
+// 月与周字段还支持 names 中的英文缩写（如 `JAN`、`MON`，不区分大小写），范围的起点不能大于终点
+func checkCronField(field string, bound [2]int, names []string) error {
+	for _, item := range strings.Split(field, ",") {
+		base, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				return fmt.Errorf("invalid step in %q", item)
+			}
+		}
+		if base == "*" || base == "?" {
+			continue
+		}
+		parts := []string{base}
+		if lo, hi, isRange := strings.Cut(base, "-"); isRange {
+			parts = []string{lo, hi}
+		}
+		values := make([]int, len(parts))
+		for j, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				n = cronName(names, part)
+				if n < 0 {
+					return fmt.Errorf("invalid value %q in %q", part, item)
+				}
+				n += bound[0]
+			}
+			if n < bound[0] || n > bound[1] {
+				return fmt.Errorf("value %d out of range [%d, %d] in %q", n, bound[0], bound[1], item)
+			}
+			values[j] = n
+		}
+		if len(values) == 2 && values[0] > values[1] {
+			return fmt.Errorf("invalid range %q", item)
+		}
+	}
+	return nil
+}
+
+// cronName 返回缩写在 names 中的位置（不区分大小写），不存在时返回 -1
+func cronName(names []string, name string) int {
+	for i, s := range names {
+		if strings.EqualFold(s, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Rate 将形如 `100/s`、`60/m`、`3600/h` 的频率换算为每秒的次数，不带单位时视为每秒，
+// 数据不存在时返回 0，格式有误、次数为负数或不是有限值（如 `NaN`、`Inf`）时返回错误
+func (i *inner) Rate(key string) (perSecond float64, err error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return 0, nil
+	}
+	count, unit, _ := strings.Cut(value, "/")
+	n, err := strconv.ParseFloat(strings.TrimSpace(count), 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("env: invalid rate %q for %q", value, key)
+	}
+	seconds, err := rateUnit(unit)
+	if err != nil {
+		return 0, fmt.Errorf("env: invalid rate %q for %q", value, key)
+	}
+	return n / seconds, nil
+}
+
+// Until 将值作为 Unix 时间戳（秒）并返回当前时间距该时间的时长，时间已过时返回负值
+func (i *inner) Until(key string, fallback ...time.Duration) time.Duration {
+	if val, ok := i.Lookup(key); ok {
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return time.Until(time.Unix(n, 0))
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// Time 将值按照 RFC3339 格式（如 `2024-01-02T15:04:05Z`）解析为时间，解析失败时返回默认值
+func (i *inner) Time(key string, fallback ...time.Time) time.Time {
+	return i.TimeLayout(key, time.RFC3339, fallback...)
+}
+
+// TimeLayout 将值按照 layout 格式解析为时间，解析失败时返回默认值
+func (i *inner) TimeLayout(key, layout string, fallback ...time.Time) time.Time {
+	if val, ok := i.Lookup(key); ok {
+		if t, err := time.Parse(layout, strings.TrimSpace(val)); err == nil {
+			return t
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return time.Time{}
+}
+
+// Bool 取布尔值，除 strconv.ParseBool 支持的值外，还支持 yes/no、on/off、y/n（忽略大小写）
 func (i *inner) Bool(key string, fallback ...bool) bool {
 	if val, ok := i.Lookup(key); ok {
-		bl, err := strconv.ParseBool(val)
+		bl, err := parseBool(val)
 		if err == nil {
 			return bl
 		}
@@ -93,10 +660,90 @@ func (i *inner) Bool(key string, fallback ...bool) bool {
 	return false
 }
 
+// BoolE 与 Bool 相同，但值存在却无法解析时返回错误（以及默认值），便于区分未设置与设置有误
+func (i *inner) BoolE(key string, fallback ...bool) (bool, error) {
+	var result bool
+	for _, value := range fallback {
+		result = value
+		break
+	}
+	val, ok := i.Lookup(key)
+	if !ok {
+		return result, nil
+	}
+	b, err := parseBool(val)
+	if err != nil {
+		return result, fmt.Errorf("env: invalid value %q for %q", val, key)
+	}
+	return b, nil
+}
+
+// BoolList 将值按 `,` 分割并逐个解析为布尔值（规则同 Bool），任意元素无效时返回默认值
+func (i *inner) BoolList(key string, fallback ...[]bool) []bool {
+	if value, ok := i.Lookup(key); ok {
+		var list []bool
+		for _, part := range strings.Split(value, ",") {
+			bl, err := parseBool(strings.TrimSpace(part))
+			if err != nil {
+				list = nil
+				break
+			}
+			list = append(list, bl)
+		}
+		if list != nil {
+			return list
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []bool{}
+}
+
+// FileMode 取八进制表示的文件权限值，支持 `0644` 与 `0o755` 两种写法，
+// 只接受权限位（不超过 `0777`），超出范围时与格式有误一样使用默认值
+func (i *inner) FileMode(key string, fallback ...os.FileMode) os.FileMode {
+	if val, ok := i.Lookup(key); ok {
+		val = strings.TrimPrefix(strings.TrimPrefix(val, "0o"), "0O")
+		if n, err := strconv.ParseUint(val, 8, 32); err == nil && n <= uint64(os.ModePerm) {
+			return os.FileMode(n)
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return 0
+}
+
+// BoolOrInt 取开关或等级值，正整数返回 (true, n)，
+// 其它整数与假值返回 (false, 0)，真值返回 (true, 0)
+func (i *inner) BoolOrInt(key string) (enabled bool, level int) {
+	val, ok := i.Lookup(key)
+	if !ok {
+		return false, 0
+	}
+	if n, err := strconv.Atoi(val); err == nil {
+		if n > 0 {
+			return true, n
+		}
+		return false, 0
+	}
+	if bl, err := parseBool(val); err == nil {
+		return bl, 0
+	}
+	return false, 0
+}
+
 // List 将值按 `,` 分割并返回
 func (i *inner) List(key string, fallback ...[]string) []string {
+	return i.ListSep(key, ",", fallback...)
+}
+
+// ListSep 将值按 sep（可以是多个字符）分割并返回，每个元素两端的空白会被去除，
+// 空元素（如末尾的分隔符产生的元素）会被保留
+func (i *inner) ListSep(key, sep string, fallback ...[]string) []string {
 	if value, ok := i.Lookup(key); ok {
-		parts := strings.Split(value, ",")
+		parts := strings.Split(value, sep)
 		for i, part := range parts {
 			parts[i] = strings.TrimSpace(part)
 		}
@@ -108,6 +755,258 @@ func (i *inner) List(key string, fallback ...[]string) []string {
 	return []string{}
 }
 
+// ListMerged 将类目键与缺省键的值分别按 `,` 分割后合并（类目键的元素在前），
+// 并去除重复及空白的元素，两者都不存在时返回空切片
+func (i *inner) ListMerged(key string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, value := range i.candidates(key) {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// ListAuto 自动识别分隔符并分割值，识别顺序为 `,`、`;`、换行符、空白字符，
+// 即值中出现逗号时总是按逗号分割；元素两端的空白会被去除，空元素会被忽略
+func (i *inner) ListAuto(key string) []string {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return []string{}
+	}
+	var parts []string
+	switch {
+	case strings.Contains(value, ","):
+		parts = strings.Split(value, ",")
+	case strings.Contains(value, ";"):
+		parts = strings.Split(value, ";")
+	case strings.Contains(value, "\n"):
+		parts = strings.Split(value, "\n")
+	default:
+		parts = strings.Fields(value)
+	}
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// ListN 将值按 `,` 分割并返回，元素数量超过 max 时视为无效值并返回默认值
+func (i *inner) ListN(key string, max int, fallback ...[]string) []string {
+	if list := i.List(key); len(list) > 0 && len(list) <= max {
+		return list
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []string{}
+}
+
+// ListEscaped 将值按 `,` 分割并返回，支持使用 `\,` 转义逗号、`\\` 转义反斜杠
+func (i *inner) ListEscaped(key string, fallback ...[]string) []string {
+	if value, ok := i.Lookup(key); ok {
+		return splitEscaped(value, ',')
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []string{}
+}
+
+// ListCSV 将值作为一条 CSV 记录解析并返回，允许使用双引号包裹含有逗号的元素，
+// 如 `"a,b","c,d"`，解析失败时返回默认值
+func (i *inner) ListCSV(key string, fallback ...[]string) []string {
+	if value, ok := i.Lookup(key); ok {
+		r := csv.NewReader(strings.NewReader(value))
+		r.TrimLeadingSpace = true
+		if parts, err := r.Read(); err == nil {
+			for i, part := range parts {
+				parts[i] = strings.TrimSpace(part)
+			}
+			return parts
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []string{}
+}
+
+// URLList 将值按 `,` 分割并逐个解析为 URL，任意元素解析失败时返回默认值
+func (i *inner) URLList(key string, fallback ...[]*url.URL) []*url.URL {
+	if value, ok := i.Lookup(key); ok {
+		var urls []*url.URL
+		for _, part := range strings.Split(value, ",") {
+			u, err := url.Parse(strings.TrimSpace(part))
+			if err != nil || !u.IsAbs() {
+				urls = nil
+				break
+			}
+			urls = append(urls, u)
+		}
+		if urls != nil {
+			return urls
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []*url.URL{}
+}
+
+// IPList 将值按 `,` 分割并逐个解析为 IP 地址，任意元素解析失败时返回默认值
+func (i *inner) IPList(key string, fallback ...[]net.IP) []net.IP {
+	if value, ok := i.Lookup(key); ok {
+		var ips []net.IP
+		for _, part := range strings.Split(value, ",") {
+			ip := net.ParseIP(strings.TrimSpace(part))
+			if ip == nil {
+				ips = nil
+				break
+			}
+			ips = append(ips, ip)
+		}
+		if ips != nil {
+			return ips
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return []net.IP{}
+}
+
+// MAC 将值解析为硬件地址，解析失败时返回默认值
+func (i *inner) MAC(key string, fallback ...net.HardwareAddr) net.HardwareAddr {
+	if value, ok := i.Lookup(key); ok {
+		if mac, err := net.ParseMAC(strings.TrimSpace(value)); err == nil {
+			return mac
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return nil
+}
+
+// WeightedMap 将值解析为带权重的映射，如 `a:3,b:1,c:2`，省略权重时默认为 1，
+// 任意权重不是非负整数时返回默认值
+func (i *inner) WeightedMap(key string, fallback ...map[string]int) map[string]int {
+	if value, ok := i.Lookup(key); ok {
+		weights := make(map[string]int)
+		for _, part := range strings.Split(value, ",") {
+			name, weight, found := strings.Cut(strings.TrimSpace(part), ":")
+			n := 1
+			if found {
+				var err error
+				if n, err = strconv.Atoi(strings.TrimSpace(weight)); err != nil || n < 0 {
+					weights = nil
+					break
+				}
+			}
+			weights[strings.TrimSpace(name)] = n
+		}
+		if weights != nil {
+			return weights
+		}
+	}
+	for _, value := range fallback {
+		return value
+	}
+	return map[string]int{}
+}
+
+// MustString 取必填的字符串值，数据不存在或值为空时 panic
+func (i *inner) MustString(key string) string {
+	value, ok := i.Lookup(key)
+	if !ok || value == "" {
+		panic(fmt.Errorf("env: missing required variable %q", key))
+	}
+	return value
+}
+
+// MustInt 取必填的整数值，数据不存在、值为空或无法解析时 panic
+func (i *inner) MustInt(key string) int {
+	value := i.MustString(key)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Errorf("env: invalid value %q for %q", value, key))
+	}
+	return n
+}
+
+// MustBool 取必填的布尔值（规则同 Bool），数据不存在、值为空或无法解析时 panic
+func (i *inner) MustBool(key string) bool {
+	value := i.MustString(key)
+	b, err := parseBool(value)
+	if err != nil {
+		panic(fmt.Errorf("env: invalid value %q for %q", value, key))
+	}
+	return b
+}
+
+// MustDuration 取必填的时长（规则同 Duration），数据不存在、值为空或无法解析时 panic
+func (i *inner) MustDuration(key string) time.Duration {
+	value := i.MustString(key)
+	d, err := parseDuration(value)
+	if err != nil {
+		panic(fmt.Errorf("env: invalid value %q for %q", value, key))
+	}
+	return d
+}
+
+// LookupEnumRequired 取必填的枚举值，数据不存在、值为空或不在 allowed 中时返回错误
+func (i *inner) LookupEnumRequired(key string, allowed []string) (string, error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("env: missing required variable %q", key)
+	}
+	for _, item := range allowed {
+		if value == item {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("env: invalid value %q for %q, allowed: %s", value, key, strings.Join(allowed, ", "))
+}
+
+// JSON 将值作为 JSON 文档解码到 out 中，数据不存在或解码失败时返回错误
+func (i *inner) JSON(key string, out any) error {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return fmt.Errorf("env: missing required variable %q", key)
+	}
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return fmt.Errorf("env: cannot decode JSON %q: %w", key, err)
+	}
+	return nil
+}
+
+// Render 将值作为 text/template 模板并使用 data 渲染
+func (i *inner) Render(key string, data any) (string, error) {
+	value, ok := i.Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("env: missing required variable %q", key)
+	}
+	tpl, err := template.New(key).Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("env: cannot parse template %q: %w", key, err)
+	}
+	var b strings.Builder
+	if err = tpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("env: cannot render template %q: %w", key, err)
+	}
+	return b.String(), nil
+}
+
 // Map 获取指定前缀的所有值
 func (i *inner) Map(prefix string) map[string]string {
 	result := map[string]string{}
@@ -124,6 +1023,32 @@ func (i *inner) Map(prefix string) map[string]string {
 	}
 }
 
+// MapStrict 与 Map 相同，但去除前缀后的键名互为别名时返回错误，而不是将它们作为不同的键返回。
+//
+// 只有大小写不同（Windows 下的环境变量不区分大小写）或 `.` 与 `_` 不同（开启 SetNormalizeKeys
+// 后可以互相读取）的键名视为别名，如 `CACHE_db.host` 与 `CACHE_DB_HOST` 去除前缀 `CACHE_` 后
+// 都指向 `DB_HOST`，错误信息中包含冲突的两个完整键名。
+func (i *inner) MapStrict(prefix string) (map[string]string, error) {
+	result := map[string]string{}
+	origins := map[string]string{}
+	next := i.iter()
+	for {
+		key, value, ok := next()
+		if !ok {
+			return result, nil
+		}
+		if strings.HasPrefix(key, prefix) {
+			name := strings.TrimPrefix(key, prefix)
+			alias := normalizeKey(name)
+			if origin, exists := origins[alias]; exists {
+				return nil, fmt.Errorf("env: keys %q and %q both map to %q", i.qualify(origin), i.qualify(key), alias)
+			}
+			origins[alias] = key
+			result[name] = strings.TrimSpace(value)
+		}
+	}
+}
+
 // Where 获取符合过滤器的所有值
 func (i *inner) Where(filter func(name, value string) bool) map[string]string {
 	result := map[string]string{}
@@ -139,39 +1064,347 @@ func (i *inner) Where(filter func(name, value string) bool) map[string]string {
 	}
 }
 
+// WhereQualified 获取符合过滤器的所有值，过滤器同时接收完整键名与短键名
+func (i *inner) WhereQualified(filter func(qualifiedKey, shortKey, value string) bool) map[string]string {
+	result := map[string]string{}
+	next := i.iter()
+	for {
+		key, value, ok := next()
+		if !ok {
+			return result
+		}
+		if filter(i.qualify(key), key, value) {
+			result[key] = value
+		}
+	}
+}
+
+// Typed 返回所有数据，并尽可能将值转换为合适的类型，判断顺序依次为：
+// 整数（int）、布尔值（仅 true/false，忽略大小写）、浮点数（float64）、
+// 带单位的时长（time.Duration，如 `30s`），均不满足时保留为字符串。
+func (i *inner) Typed() map[string]any {
+	result := map[string]any{}
+	next := i.iter()
+	for {
+		key, value, ok := next()
+		if !ok {
+			return result
+		}
+		result[key] = infer(value)
+	}
+}
+
+// ExportEnviron 以 `KEY=value` 的形式导出所有数据，可直接用于 exec.Cmd.Env
+func (i *inner) ExportEnviron() []string {
+	var result []string
+	next := i.iter()
+	for {
+		key, value, ok := next()
+		if !ok {
+			return result
+		}
+		result = append(result, key+"="+value)
+	}
+}
+
 // Fill 将环境变量填充到指定结构体
 func (i *inner) Fill(structure any) error {
+	return i.fill(structure, nil)
+}
+
+// FillAll 与 Fill 相同，但遇到无法转换的字段时会继续填充其余字段，
+// 最后返回包含所有失败字段（及其键名）的聚合错误
+func (i *inner) FillAll(structure any) error {
+	var errs []error
+	if err := i.fill(structure, &errs); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// fill 填充结构体，errs 不为 nil 时收集字段错误并继续，否则在第一个错误处返回
+func (i *inner) fill(structure any, errs *[]error) error {
 	inputType := reflect.TypeOf(structure)
 
 	if inputType != nil && inputType.Kind() == reflect.Ptr && inputType.Elem().Kind() == reflect.Struct {
-		return i.fillStruct(reflect.ValueOf(structure).Elem())
+		appEnv, _ := i.root("APP_ENV")
+		_, err := i.fillStruct(reflect.ValueOf(structure).Elem(), strings.ToLower(appEnv), map[reflect.Type]bool{}, errs)
+		return err
 	}
 
 	return errors.New("env: invalid structure")
 }
 
-func (i *inner) fillStruct(s reflect.Value) error {
+// Bind 将值转换为 target（必须是非 nil 的指针）所指向的类型并赋值，转换规则与 Fill 相同；
+// 数据不存在或值为空时使用第一个默认值，没有默认值时保持 target 不变
+func (i *inner) Bind(key string, target any, fallback ...any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("env: invalid bind target")
+	}
+	elem := rv.Elem()
+	if value, ok := i.Lookup(key); ok && value != "" {
+		v, err := convertField(value, elem.Type())
+		if err != nil {
+			return fmt.Errorf("env: cannot bind %q; err: %v", key, err)
+		}
+		elem.Set(v)
+		return nil
+	}
+	for _, value := range fallback {
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().AssignableTo(elem.Type()) {
+			return fmt.Errorf("env: cannot bind %q; fallback %T is not assignable to %s", key, value, elem.Type())
+		}
+		elem.Set(v)
+		return nil
+	}
+	return nil
+}
+
+// MustFill 与 Fill 相同，但发生错误时直接 panic
+func (i *inner) MustFill(structure any) {
+	if err := i.Fill(structure); err != nil {
+		panic(err)
+	}
+}
+
+// fillStruct 填充结构体，值为空时依次使用 `default-{APP_ENV}` 与 `default` 标签作为默认值，
+// 第一个返回值表示是否有字段从数据中取得了值（不含默认值）。
+//
+// 值为 nil 的结构体指针字段仅在有对应数据时才会分配并填充，否则保持 nil；
+// seen 记录当前路径上的结构体类型，自引用类型（如链表节点）的 nil 指针不会被分配；
+// errs 不为 nil 时字段转换错误会追加到其中，并继续填充其余字段。
+func (i *inner) fillStruct(s reflect.Value, appEnv string, seen map[reflect.Type]bool, errs *[]error) (bool, error) {
+	seen[s.Type()] = true
+	defer delete(seen, s.Type())
+	filled := false
 	for j := 0; j < s.NumField(); j++ {
 		if t, exist := s.Type().Field(j).Tag.Lookup("env"); exist {
-			if osv := i.String(t); osv != "" {
-				v, err := cast.FromType(osv, s.Type().Field(j).Type)
+			osv := i.String(t)
+			if osv != "" {
+				filled = true
+			} else {
+				osv = defaultTag(s.Type().Field(j).Tag, appEnv)
+			}
+			if osv != "" {
+				v, err := convertField(osv, s.Type().Field(j).Type)
 				if err != nil {
-					return fmt.Errorf("env: cannot set `%v` field; err: %v", s.Type().Field(j).Name, err)
+					err = fmt.Errorf("env: cannot set `%v` field from %q; err: %v", s.Type().Field(j).Name, t, err)
+					if errs == nil {
+						return filled, err
+					}
+					*errs = append(*errs, err)
+					continue
 				}
 				ptr := reflect.NewAt(s.Field(j).Type(), unsafe.Pointer(s.Field(j).UnsafeAddr())).Elem()
-				ptr.Set(reflect.ValueOf(v))
+				ptr.Set(v)
 			}
 		} else if s.Type().Field(j).Type.Kind() == reflect.Struct {
-			if err := i.fillStruct(s.Field(j)); err != nil {
-				return err
+			ok, err := i.fillStruct(s.Field(j), appEnv, seen, errs)
+			if err != nil {
+				return filled, err
 			}
-		} else if s.Type().Field(j).Type.Kind() == reflect.Ptr {
-			if s.Field(j).IsZero() == false && s.Field(j).Elem().Type().Kind() == reflect.Struct {
-				if err := i.fillStruct(s.Field(j).Elem()); err != nil {
-					return err
+			filled = filled || ok
+		} else if s.Type().Field(j).Type.Kind() == reflect.Ptr && s.Type().Field(j).Type.Elem().Kind() == reflect.Struct {
+			if !s.Field(j).IsNil() {
+				ok, err := i.fillStruct(s.Field(j).Elem(), appEnv, seen, errs)
+				if err != nil {
+					return filled, err
 				}
+				filled = filled || ok
+				continue
+			}
+			if seen[s.Type().Field(j).Type.Elem()] {
+				continue
+			}
+			// 先填充到新分配的值上，有对应数据时才赋值给字段
+			elem := reflect.New(s.Type().Field(j).Type.Elem())
+			ok, err := i.fillStruct(elem.Elem(), appEnv, seen, errs)
+			if err != nil {
+				return filled, err
+			}
+			if ok {
+				ptr := reflect.NewAt(s.Field(j).Type(), unsafe.Pointer(s.Field(j).UnsafeAddr())).Elem()
+				ptr.Set(elem)
+				filled = true
 			}
 		}
 	}
-	return nil
+	return filled, nil
+}
+
+// convertField 将字符串转换为字段类型的值，切片按 `,` 分割（[]byte 除外），
+// 映射按 `k=v;k2=v2` 的格式解析，元素与键值均通过 cast.FromType 转换
+func convertField(value string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		parts := strings.Split(value, ",")
+		result := reflect.MakeSlice(t, 0, len(parts))
+		for _, part := range parts {
+			v, err := convertField(strings.TrimSpace(part), t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result = reflect.Append(result, v)
+		}
+		return result, nil
+	case t.Kind() == reflect.Map:
+		result := reflect.MakeMap(t)
+		for _, pair := range strings.Split(value, ";") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			k, v, found := strings.Cut(pair, "=")
+			if !found {
+				return reflect.Value{}, fmt.Errorf("invalid map entry %q", pair)
+			}
+			key, err := convertField(strings.TrimSpace(k), t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elem, err := convertField(strings.TrimSpace(v), t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.SetMapIndex(key, elem)
+		}
+		return result, nil
+	}
+	v, err := cast.FromType(value, t)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// defaultTag 返回字段的默认值，优先使用与运行环境对应的 `default-{APP_ENV}` 标签
+func defaultTag(tag reflect.StructTag, appEnv string) string {
+	if appEnv != "" {
+		if value, ok := tag.Lookup("default-" + appEnv); ok {
+			return value
+		}
+	}
+	return tag.Get("default")
+}
+
+// splitEscaped 使用分隔符 sep 分割字符串，被反斜杠转义的分隔符不参与分割，
+// 分割完成后会去除转义符并清除每个元素两端的空白字符。
+func splitEscaped(value string, sep byte) []string {
+	var parts []string
+	var b strings.Builder
+	for j := 0; j < len(value); j++ {
+		c := value[j]
+		if c == '\\' && j+1 < len(value) && (value[j+1] == sep || value[j+1] == '\\') {
+			j++
+			b.WriteByte(value[j])
+			continue
+		}
+		if c == sep {
+			parts = append(parts, strings.TrimSpace(b.String()))
+			b.Reset()
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return append(parts, strings.TrimSpace(b.String()))
+}
+
+// DurationUnit 不带单位的整数时长所使用的单位，默认为秒，
+// 即 `TIMEOUT=30` 与 `TIMEOUT=30s` 相同，带单位的值不受影响
+var DurationUnit = time.Second
+
+// parseDuration 解析时长，所有查询器（包括签名查询器的类目键和缺省键）
+// 都通过该函数解析，以保证同样的值在任何作用域下都得到相同的结果。
+func parseDuration(val string) (time.Duration, error) {
+	if n, err := strconv.Atoi(val); err == nil {
+		return time.Duration(n) * DurationUnit, nil
+	}
+	return time.ParseDuration(val)
+}
+
+// parseBool 解析布尔值，在 strconv.ParseBool 的基础上支持 yes/no、on/off、y/n
+func parseBool(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(val)
+}
+
+// 容量单位对应的字节数，KB、MB 等使用 1000 进制，KiB、MiB 等使用 1024 进制
+var sizeUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseSize 解析带容量单位的数值（单位不区分大小写），如 `10MB`、`500KiB`、`1.5GB`
+func parseSize(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	j := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if j == -1 {
+		j = len(value)
+	}
+	n, err := strconv.ParseFloat(value[:j], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(value[j:]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in %q", value)
+	}
+	return n * unit, nil
+}
+
+// rateUnit 返回频率单位对应的秒数，空字符串视为秒
+func rateUnit(unit string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "", "s", "sec", "second":
+		return 1, nil
+	case "m", "min", "minute":
+		return 60, nil
+	case "h", "hour":
+		return 3600, nil
+	case "d", "day":
+		return 86400, nil
+	}
+	return 0, fmt.Errorf("unknown rate unit %q", unit)
+}
+
+// parsePercent 解析比例值，以 `%` 结尾的值会除以 100
+func parsePercent(val string) (float64, error) {
+	if strings.HasSuffix(val, "%") {
+		n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(val, "%")), 64)
+		return n / 100, err
+	}
+	return strconv.ParseFloat(val, 64)
+}
+
+// infer 推断值的类型，顺序参见 Typed
+func infer(value string) any {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return strings.EqualFold(value, "true")
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return n
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	return value
 }
@@ -0,0 +1,1177 @@
+package env
+
+import (
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListEscaped(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"NAMES":     `Doe\, John,Roe\, Jane`,
+		"PATHS":     `C:\\tmp,D:\\data`,
+		"TRAILING":  `a\\,b`,
+		"UNESCAPED": `a\b,c`,
+	})
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"NAMES", []string{"Doe, John", "Roe, Jane"}},
+		{"PATHS", []string{`C:\tmp`, `D:\data`}},
+		{"TRAILING", []string{`a\`, "b"}},
+		{"UNESCAPED", []string{`a\b`, "c"}},
+	}
+	for _, tt := range tests {
+		if got := e.ListEscaped(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListEscaped(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := e.ListEscaped("MISSING", []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("ListEscaped(MISSING) = %q, want fallback", got)
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"BUDGET":  "1.5e6",
+		"GROUPED": "1,000.50",
+		"BAD":     "abc",
+	})
+	if got := e.Float64("BUDGET"); got != 1.5e6 {
+		t.Errorf("Float64(BUDGET) = %v, want 1.5e6", got)
+	}
+	if got := e.Float64("GROUPED", -1); got != -1 {
+		t.Errorf("Float64(GROUPED) = %v, want fallback", got)
+	}
+	if got := e.Float64Locale("GROUPED"); got != 1000.5 {
+		t.Errorf("Float64Locale(GROUPED) = %v, want 1000.5", got)
+	}
+	if got := e.Float64Locale("BUDGET"); got != 1.5e6 {
+		t.Errorf("Float64Locale(BUDGET) = %v, want 1.5e6", got)
+	}
+	if got := e.Float64Locale("BAD", 2); got != 2 {
+		t.Errorf("Float64Locale(BAD) = %v, want fallback", got)
+	}
+}
+
+func TestURLList(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PEERS": "http://a, https://b:8443/path",
+		"BAD":   "http://a,not a url",
+		"EMPTY": "",
+	})
+	got := e.URLList("PEERS")
+	if len(got) != 2 || got[0].String() != "http://a" || got[1].Host != "b:8443" {
+		t.Errorf("URLList(PEERS) = %v", got)
+	}
+	fallback := []*url.URL{{Scheme: "http", Host: "fallback"}}
+	if got := e.URLList("BAD", fallback); !reflect.DeepEqual(got, fallback) {
+		t.Errorf("URLList(BAD) = %v, want fallback", got)
+	}
+	if got := e.URLList("EMPTY"); len(got) != 0 {
+		t.Errorf("URLList(EMPTY) = %v, want empty", got)
+	}
+	if got := e.URLList("EMPTY", fallback); !reflect.DeepEqual(got, fallback) {
+		t.Errorf("URLList(EMPTY) = %v, want fallback", got)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"FILE_MODE": "0644",
+		"DIR_MODE":  "0o755",
+		"UMASK":     "022",
+		"BAD":       "0999",
+		"DIR_BITS":  "40755",
+		"SETUID":    "4755",
+	})
+	tests := []struct {
+		key  string
+		want os.FileMode
+	}{
+		{"FILE_MODE", 0o644},
+		{"DIR_MODE", 0o755},
+		{"UMASK", 0o022},
+		{"BAD", 0o600},
+		{"DIR_BITS", 0o600},
+		{"SETUID", 0o600},
+		{"MISSING", 0o600},
+	}
+	for _, tt := range tests {
+		if got := e.FileMode(tt.key, 0o600); got != tt.want {
+			t.Errorf("FileMode(%q) = %o, want %o", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLookupEnumRequired(t *testing.T) {
+	e := newTestEnviron(map[string]string{"LOG_LEVEL": "info", "MODE": "fast"})
+	allowed := []string{"debug", "info", "warn"}
+
+	if got, err := e.LookupEnumRequired("LOG_LEVEL", allowed); err != nil || got != "info" {
+		t.Errorf("LookupEnumRequired(LOG_LEVEL) = %q, %v", got, err)
+	}
+
+	_, err := e.LookupEnumRequired("MODE", allowed)
+	if err == nil || !strings.Contains(err.Error(), `"fast"`) || !strings.Contains(err.Error(), "debug, info, warn") {
+		t.Errorf("LookupEnumRequired(MODE) error = %v, want value and allowed set", err)
+	}
+
+	_, err = e.LookupEnumRequired("MISSING", allowed)
+	if err == nil || err.Error() != `env: missing required variable "MISSING"` {
+		t.Errorf("LookupEnumRequired(MISSING) error = %v", err)
+	}
+}
+
+func TestUntil(t *testing.T) {
+	now := time.Now()
+	e := newTestEnviron(map[string]string{
+		"FUTURE": strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+		"PAST":   strconv.FormatInt(now.Add(-time.Hour).Unix(), 10),
+		"BAD":    "tomorrow",
+	})
+	if got := e.Until("FUTURE"); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("Until(FUTURE) = %v, want about 1h", got)
+	}
+	if got := e.Until("PAST"); got >= -59*time.Minute || got < -61*time.Minute {
+		t.Errorf("Until(PAST) = %v, want about -1h", got)
+	}
+	if got := e.Until("BAD", time.Second); got != time.Second {
+		t.Errorf("Until(BAD) = %v, want fallback", got)
+	}
+}
+
+func TestMapStrict(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"CACHE_HOST": "localhost",
+		"CACHE_PORT": "6379",
+		"OTHER":      "x",
+	})
+	got, err := e.MapStrict("CACHE_")
+	if want := map[string]string{"HOST": "localhost", "PORT": "6379"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MapStrict() = %v, %v, want %v", got, err, want)
+	}
+
+	e.Set("CACHE_db.host", "a")
+	e.Set("CACHE_DB_HOST", "b")
+	if got := e.Map("CACHE_"); len(got) != 4 {
+		t.Errorf("Map() = %v, want both aliases kept", got)
+	}
+	_, err = e.MapStrict("CACHE_")
+	if err == nil || !strings.Contains(err.Error(), `"CACHE_db.host"`) || !strings.Contains(err.Error(), `"CACHE_DB_HOST"`) {
+		t.Errorf("MapStrict() error = %v, want both conflicting keys", err)
+	}
+
+	// 签名查询器中类目键覆盖缺省键是正常的优先级，而不是冲突
+	s := newTestEnviron(map[string]string{
+		"CACHE_DATABASE":      "1",
+		"CACHE_BOOK_DATABASE": "10",
+		"CACHE_BOOK_Scope":    "books",
+		"CACHE_SCOPE":         "app",
+	}).Signed("CACHE", "BOOK")
+	_, err = s.MapStrict("")
+	if err == nil || !strings.Contains(err.Error(), `"CACHE_BOOK_Scope"`) || !strings.Contains(err.Error(), `"CACHE_SCOPE"`) {
+		t.Errorf("signer MapStrict() error = %v, want CACHE_BOOK_Scope and CACHE_SCOPE", err)
+	}
+}
+
+func TestBoolOrInt(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"TRUE":  "true",
+		"FALSE": "false",
+		"ZERO":  "0",
+		"THREE": "3",
+		"YES":   "yes",
+		"BAD":   "loud",
+	})
+	tests := []struct {
+		key     string
+		enabled bool
+		level   int
+	}{
+		{"TRUE", true, 0},
+		{"FALSE", false, 0},
+		{"ZERO", false, 0},
+		{"THREE", true, 3},
+		{"YES", true, 0},
+		{"BAD", false, 0},
+		{"MISSING", false, 0},
+	}
+	for _, tt := range tests {
+		if enabled, level := e.BoolOrInt(tt.key); enabled != tt.enabled || level != tt.level {
+			t.Errorf("BoolOrInt(%q) = (%v, %d), want (%v, %d)", tt.key, enabled, level, tt.enabled, tt.level)
+		}
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"DECIMAL": "0.1",
+		"PERCENT": "10%",
+		"ONE":     "1",
+		"HIGH":    "150%",
+		"LOW":     "-0.5",
+		"NAN":     "NaN",
+		"NAN_PCT": "NaN%",
+		"BAD":     "often",
+	})
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"DECIMAL", 0.1},
+		{"PERCENT", 0.1},
+		{"ONE", 1},
+		{"HIGH", 1},
+		{"LOW", 0},
+		{"NAN", 0.5},
+		{"NAN_PCT", 0.5},
+		{"BAD", 0.5},
+		{"MISSING", 0.5},
+	}
+	for _, tt := range tests {
+		if got := e.SampleRate(tt.key, 0.5); got != tt.want {
+			t.Errorf("SampleRate(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestListCSV(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"TAGS":   `"a,b","c,d"`,
+		"PLAIN":  "a, b ,c",
+		"QUOTES": `"say ""hi""",x`,
+		"BAD":    `"unterminated,x`,
+	})
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"TAGS", []string{"a,b", "c,d"}},
+		{"PLAIN", []string{"a", "b", "c"}},
+		{"QUOTES", []string{`say "hi"`, "x"}},
+		{"BAD", []string{"fallback"}},
+		{"MISSING", []string{"fallback"}},
+	}
+	for _, tt := range tests {
+		if got := e.ListCSV(tt.key, []string{"fallback"}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListCSV(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"GREETING": "Hello, {{.Name}}!",
+		"BAD":      "Hello, {{.Name",
+	})
+	got, err := e.Render("GREETING", struct{ Name string }{"Ada"})
+	if err != nil || got != "Hello, Ada!" {
+		t.Errorf("Render(struct) = %q, %v", got, err)
+	}
+	got, err = e.Render("GREETING", map[string]string{"Name": "Bob"})
+	if err != nil || got != "Hello, Bob!" {
+		t.Errorf("Render(map) = %q, %v", got, err)
+	}
+	if _, err = e.Render("BAD", nil); err == nil || !strings.Contains(err.Error(), "cannot parse template") {
+		t.Errorf("Render(BAD) error = %v, want a parse error", err)
+	}
+	if _, err = e.Render("GREETING", 42); err == nil {
+		t.Error("Render with unusable data should fail")
+	}
+	if _, err = e.Render("MISSING", nil); err == nil {
+		t.Error("Render(MISSING) should fail")
+	}
+}
+
+func TestTyped(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PORT":    "8080",
+		"DEBUG":   "true",
+		"RATIO":   "0.5",
+		"TIMEOUT": "30s",
+		"NAME":    "x",
+		"YES":     "yes",
+	})
+	want := map[string]any{
+		"PORT":    8080,
+		"DEBUG":   true,
+		"RATIO":   0.5,
+		"TIMEOUT": 30 * time.Second,
+		"NAME":    "x",
+		"YES":     "yes",
+	}
+	if got := e.Typed(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Typed() = %#v, want %#v", got, want)
+	}
+}
+
+func TestListN(t *testing.T) {
+	e := newTestEnviron(map[string]string{"HOSTS": "a,b,c"})
+	if got := e.ListN("HOSTS", 3); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("ListN(HOSTS, 3) = %q", got)
+	}
+	if got := e.ListN("HOSTS", 2, []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("ListN(HOSTS, 2) = %q, want fallback", got)
+	}
+	if got := e.ListN("HOSTS", 2); len(got) != 0 {
+		t.Errorf("ListN(HOSTS, 2) = %q, want empty", got)
+	}
+}
+
+func TestStringFunc(t *testing.T) {
+	e := newTestEnviron(map[string]string{"HOST": "example.com"})
+	var calls int
+	provider := func() string {
+		calls++
+		return "fallback"
+	}
+	if got := e.StringFunc("HOST", provider); got != "example.com" || calls != 0 {
+		t.Errorf("StringFunc(HOST) = %q with %d calls, want example.com without calls", got, calls)
+	}
+	for n := 1; n <= 2; n++ {
+		if got := e.StringFunc("MISSING", provider); got != "fallback" || calls != n {
+			t.Errorf("StringFunc(MISSING) = %q with %d calls, want fallback with %d", got, calls, n)
+		}
+	}
+}
+
+func TestByteSlice(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"VALID":   "10, 20,255",
+		"RANGE":   "10,256",
+		"NUMERIC": "10,x",
+	})
+	fallback := []byte{1}
+	tests := []struct {
+		key  string
+		want []byte
+	}{
+		{"VALID", []byte{10, 20, 255}},
+		{"RANGE", fallback},
+		{"NUMERIC", fallback},
+		{"MISSING", fallback},
+	}
+	for _, tt := range tests {
+		if got := e.ByteSlice(tt.key, fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ByteSlice(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if got := e.ByteSlice("NUMERIC"); len(got) != 0 {
+		t.Errorf("ByteSlice(NUMERIC) = %v without fallback, want empty", got)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PLAIN":    "1s, 2s,5s",
+		"REPEAT":   "1s,2s x3,5s",
+		"ZERO":     "1s x0",
+		"INVALID":  "1s,soon",
+		"HUGE":     "1s x9223372036854775807",
+		"TOO_MANY": "1s x65536,2s",
+		"MAX":      "1s x65535,2s",
+	})
+	tests := []struct {
+		key  string
+		want []time.Duration
+	}{
+		{"PLAIN", []time.Duration{time.Second, 2 * time.Second, 5 * time.Second}},
+		{"REPEAT", []time.Duration{time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second, 5 * time.Second}},
+		{"ZERO", []time.Duration{}},
+		{"INVALID", []time.Duration{}},
+		{"HUGE", []time.Duration{}},
+		{"TOO_MANY", []time.Duration{}},
+		{"MISSING", []time.Duration{}},
+	}
+	for _, tt := range tests {
+		if got := e.Schedule(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Schedule(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if got := e.Schedule("MAX"); len(got) != maxSchedule {
+		t.Errorf("Schedule(MAX) has %d elements, want %d", len(got), maxSchedule)
+	}
+}
+
+func TestMustFill(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	mustPanic := func(name string, e Environ, structure any) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("MustFill should panic on %s", name)
+			}
+		}()
+		e.MustFill(structure)
+	}
+
+	valid := newTestEnviron(map[string]string{"PORT": "8080"})
+	var c config
+	valid.MustFill(&c)
+	if c.Port != 8080 {
+		t.Errorf("Port = %d, want 8080", c.Port)
+	}
+
+	mustPanic("a non-pointer", valid, c)
+	mustPanic("a conversion error", newTestEnviron(map[string]string{"PORT": "http"}), &c)
+}
+
+func TestFillDefaultForAppEnv(t *testing.T) {
+	type config struct {
+		LogLevel string `env:"LOG_LEVEL" default:"info" default-dev:"debug"`
+	}
+	tests := []struct {
+		appEnv string
+		want   string
+	}{
+		{"dev", "debug"},
+		{"DEV", "debug"},
+		{"prod", "info"},
+		{"", "info"},
+	}
+	for _, tt := range tests {
+		var c config
+		if err := newTestEnviron(map[string]string{"APP_ENV": tt.appEnv}).Fill(&c); err != nil {
+			t.Fatal(err)
+		}
+		if c.LogLevel != tt.want {
+			t.Errorf("APP_ENV=%q: LogLevel = %q, want %q", tt.appEnv, c.LogLevel, tt.want)
+		}
+	}
+
+	var c config
+	if err := newTestEnviron(map[string]string{"APP_ENV": "dev", "LOG_LEVEL": "warn"}).Fill(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, explicit values must win over defaults", c.LogLevel)
+	}
+}
+
+func TestIPList(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"VALID": "8.8.8.8, 1.1.1.1,::1",
+		"BAD":   "8.8.8.8,999.1.1.1",
+		"EMPTY": ",",
+	})
+	fallback := []net.IP{net.IPv4(127, 0, 0, 1)}
+	tests := []struct {
+		key  string
+		want []net.IP
+	}{
+		{"VALID", []net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("1.1.1.1"), net.ParseIP("::1")}},
+		{"BAD", fallback},
+		{"EMPTY", fallback},
+		{"MISSING", fallback},
+	}
+	for _, tt := range tests {
+		if got := e.IPList(tt.key, fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IPList(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if got := e.IPList("BAD"); len(got) != 0 {
+		t.Errorf("IPList(BAD) = %v without fallback, want empty", got)
+	}
+}
+
+func TestWeightedMap(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"VALID":    "a:3, b:1,c:2",
+		"DEFAULT":  "a,b:2",
+		"NUMERIC":  "a:3,b:x",
+		"NEGATIVE": "a:-1",
+	})
+	fallback := map[string]int{"z": 1}
+	tests := []struct {
+		key  string
+		want map[string]int
+	}{
+		{"VALID", map[string]int{"a": 3, "b": 1, "c": 2}},
+		{"DEFAULT", map[string]int{"a": 1, "b": 2}},
+		{"NUMERIC", fallback},
+		{"NEGATIVE", fallback},
+		{"MISSING", fallback},
+	}
+	for _, tt := range tests {
+		if got := e.WeightedMap(tt.key, fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WeightedMap(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIntRanges(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PORTS":    "8080, 9000-9003,22",
+		"REVERSED": "10-1",
+		"INVALID":  "80,http",
+		"HUGE":     "0-9223372036854775807",
+		"TOO_MANY": "1-65536,0",
+		"MAX":      "1-65536",
+	})
+	got, err := e.IntRanges("PORTS")
+	if want := []int{8080, 9000, 9001, 9002, 9003, 22}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("IntRanges(PORTS) = %v, %v, want %v", got, err, want)
+	}
+	for _, key := range []string{"REVERSED", "INVALID", "HUGE", "TOO_MANY"} {
+		if got, err := e.IntRanges(key); err == nil {
+			t.Errorf("IntRanges(%s) = %d values, want an error", key, len(got))
+		}
+	}
+	if got, err := e.IntRanges("MAX"); err != nil || len(got) != maxIntRanges {
+		t.Errorf("IntRanges(MAX) = %d values, %v, want %d", len(got), err, maxIntRanges)
+	}
+	if got, err := e.IntRanges("MISSING"); got != nil || err != nil {
+		t.Errorf("IntRanges(MISSING) = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	useDefault(t, map[string]string{"QPS": "12.5", "HUGE": "1e40", "BAD": "fast"})
+	if got := Float32("QPS"); got != 12.5 {
+		t.Errorf("Float32(QPS) = %v, want 12.5", got)
+	}
+	if got := Float64("QPS"); got != 12.5 {
+		t.Errorf("Float64(QPS) = %v, want 12.5", got)
+	}
+	for _, key := range []string{"HUGE", "BAD", "MISSING"} {
+		if got := Float32(key, 1); got != 1 {
+			t.Errorf("Float32(%s) = %v, want fallback 1", key, got)
+		}
+	}
+	if got := Float32("BAD"); got != 0 {
+		t.Errorf("Float32(BAD) = %v without fallback, want 0", got)
+	}
+}
+
+func TestInt64AndUint(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"ID":          "9223372036854775807",
+		"ID_OVERFLOW": "9223372036854775808",
+		"NEGATIVE":    "-1",
+		"UINT64_MAX":  "18446744073709551615",
+		"UINT64_OVER": "18446744073709551616",
+		"HEX":         "0x10",
+	})
+	if got := e.Int64("ID"); got != math.MaxInt64 {
+		t.Errorf("Int64(ID) = %d, want MaxInt64", got)
+	}
+	if got := e.Int64("ID_OVERFLOW", 7); got != 7 {
+		t.Errorf("Int64(ID_OVERFLOW) = %d, want fallback 7", got)
+	}
+	if got := e.Int64("NEGATIVE"); got != -1 {
+		t.Errorf("Int64(NEGATIVE) = %d, want -1", got)
+	}
+	if got := e.Uint64("UINT64_MAX"); got != math.MaxUint64 {
+		t.Errorf("Uint64(UINT64_MAX) = %d, want MaxUint64", got)
+	}
+	for _, key := range []string{"UINT64_OVER", "NEGATIVE", "HEX", "MISSING"} {
+		if got := e.Uint64(key, 7); got != 7 {
+			t.Errorf("Uint64(%s) = %d, want fallback 7", key, got)
+		}
+		if got := e.Uint(key, 7); got != 7 {
+			t.Errorf("Uint(%s) = %d, want fallback 7", key, got)
+		}
+	}
+	if got := e.Uint("ID", 7); strconv.IntSize == 64 && uint64(got) != math.MaxInt64 {
+		t.Errorf("Uint(ID) = %d, want MaxInt64", got)
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	e := newTestEnviron(map[string]string{"BARE": "30", "SUFFIXED": "30s", "MILLIS": "500ms", "BAD": "soon"})
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"BARE", 30 * time.Second},
+		{"SUFFIXED", 30 * time.Second},
+		{"MILLIS", 500 * time.Millisecond},
+		{"BAD", time.Minute},
+	}
+	for _, tt := range tests {
+		if got := e.Duration(tt.key, time.Minute); got != tt.want {
+			t.Errorf("Duration(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	prev := DurationUnit
+	t.Cleanup(func() { DurationUnit = prev })
+	DurationUnit = time.Millisecond
+	if got := e.Duration("BARE"); got != 30*time.Millisecond {
+		t.Errorf("Duration(BARE) = %v with DurationUnit=1ms, want 30ms", got)
+	}
+	if got := e.Duration("SUFFIXED"); got != 30*time.Second {
+		t.Errorf("Duration(SUFFIXED) = %v with DurationUnit=1ms, want 30s", got)
+	}
+}
+
+func TestBoolList(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"FLAGS":   "true, false,1",
+		"WORDS":   "yes,no,ON,off",
+		"INVALID": "true,maybe",
+	})
+	fallback := []bool{true}
+	tests := []struct {
+		key  string
+		want []bool
+	}{
+		{"FLAGS", []bool{true, false, true}},
+		{"WORDS", []bool{true, false, true, false}},
+		{"INVALID", fallback},
+		{"MISSING", fallback},
+	}
+	for _, tt := range tests {
+		if got := e.BoolList(tt.key, fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BoolList(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PER_SECOND": "100/s",
+		"PER_MINUTE": "60/m",
+		"PER_HOUR":   "3600 / h",
+		"BARE":       "12.5",
+		"BAD_COUNT":  "many/s",
+		"BAD_UNIT":   "10/fortnight",
+		"NEGATIVE":   "-5/s",
+		"NAN":        "NaN/s",
+		"INF":        "Inf",
+	})
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"PER_SECOND", 100},
+		{"PER_MINUTE", 1},
+		{"PER_HOUR", 1},
+		{"BARE", 12.5},
+		{"MISSING", 0},
+	}
+	for _, tt := range tests {
+		if got, err := e.Rate(tt.key); err != nil || got != tt.want {
+			t.Errorf("Rate(%s) = %v, %v, want %v", tt.key, got, err, tt.want)
+		}
+	}
+	for _, key := range []string{"BAD_COUNT", "BAD_UNIT", "NEGATIVE", "NAN", "INF"} {
+		if _, err := e.Rate(key); err == nil {
+			t.Errorf("Rate(%s) should fail", key)
+		}
+	}
+}
+
+func TestFillNilStructPointer(t *testing.T) {
+	type DBConfig struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT" default:"5432"`
+	}
+	type node struct {
+		Name string `env:"NODE_NAME"`
+		Next *node
+	}
+	type App struct {
+		Name  string `env:"APP_NAME"`
+		DB    *DBConfig
+		Cache *struct {
+			Host string `env:"CACHE_HOST"`
+		}
+		Node node
+	}
+
+	var app App
+	e := newTestEnviron(map[string]string{"APP_NAME": "demo", "DB_HOST": "db.local", "NODE_NAME": "a"})
+	if err := e.Fill(&app); err != nil {
+		t.Fatal(err)
+	}
+	if app.DB == nil || *app.DB != (DBConfig{"db.local", 5432}) {
+		t.Errorf("DB = %+v, want an allocated and filled config", app.DB)
+	}
+	if app.Cache != nil {
+		t.Errorf("Cache = %+v, want nil without matching variables", app.Cache)
+	}
+	if app.Node.Name != "a" || app.Node.Next != nil {
+		t.Errorf("Node = %+v, self-referencing pointers must stay nil", app.Node)
+	}
+
+	// 默认值不会导致分配
+	var empty App
+	if err := newTestEnviron(nil).Fill(&empty); err != nil {
+		t.Fatal(err)
+	}
+	if empty.DB != nil {
+		t.Errorf("DB = %+v, defaults alone must not allocate", empty.DB)
+	}
+
+	// 已分配的指针直接填充
+	existing := &DBConfig{Host: "keep"}
+	app = App{DB: existing}
+	if err := newTestEnviron(map[string]string{"DB_PORT": "6543"}).Fill(&app); err != nil {
+		t.Fatal(err)
+	}
+	if app.DB != existing || *existing != (DBConfig{"keep", 6543}) {
+		t.Errorf("DB = %+v, want the existing pointer filled in place", app.DB)
+	}
+}
+
+func TestFillSlicesAndMaps(t *testing.T) {
+	type config struct {
+		Hosts   []string          `env:"HOSTS"`
+		Ports   []int             `env:"PORTS"`
+		Labels  map[string]string `env:"LABELS"`
+		Weights map[string]int    `env:"WEIGHTS"`
+		Empty   []string          `env:"EMPTY"`
+		Default []int             `env:"DEFAULT" default:"1,2"`
+	}
+	var c config
+	e := newTestEnviron(map[string]string{
+		"HOSTS":   "a, b,c",
+		"PORTS":   "1,2,3",
+		"LABELS":  "env=prod; team=core;",
+		"WEIGHTS": "a=1;b=2",
+	})
+	if err := e.Fill(&c); err != nil {
+		t.Fatal(err)
+	}
+	want := config{
+		Hosts:   []string{"a", "b", "c"},
+		Ports:   []int{1, 2, 3},
+		Labels:  map[string]string{"env": "prod", "team": "core"},
+		Weights: map[string]int{"a": 1, "b": 2},
+		Default: []int{1, 2},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Fill() = %+v, want %+v", c, want)
+	}
+	if c.Empty != nil {
+		t.Errorf("Empty = %#v, want nil for a missing value", c.Empty)
+	}
+
+	for key, value := range map[string]string{"PORTS": "1,x", "WEIGHTS": "a=x", "LABELS": "broken"} {
+		if err := newTestEnviron(map[string]string{key: value}).Fill(&config{}); err == nil {
+			t.Errorf("Fill() with %s=%s should fail", key, value)
+		}
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	e := newTestEnviron(map[string]string{"NIGHTLY": "02:00", "LATE": " 23:59", "BAD": "25:00", "SHORT": "2"})
+	tests := []struct {
+		key          string
+		hour, minute int
+	}{
+		{"NIGHTLY", 2, 0},
+		{"LATE", 23, 59},
+	}
+	for _, tt := range tests {
+		if hour, minute, err := e.TimeOfDay(tt.key); err != nil || hour != tt.hour || minute != tt.minute {
+			t.Errorf("TimeOfDay(%s) = %d, %d, %v, want %d, %d", tt.key, hour, minute, err, tt.hour, tt.minute)
+		}
+	}
+	for _, key := range []string{"BAD", "SHORT", "MISSING"} {
+		if _, _, err := e.TimeOfDay(key); err == nil {
+			t.Errorf("TimeOfDay(%s) should fail", key)
+		}
+	}
+}
+
+func TestMAC(t *testing.T) {
+	e := newTestEnviron(map[string]string{"VALID": "00:1a:2b:3c:4d:5e", "BAD": "00:1a:2b"})
+	want := net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}
+	if got := e.MAC("VALID"); !reflect.DeepEqual(got, want) {
+		t.Errorf("MAC(VALID) = %v, want %v", got, want)
+	}
+	if got := e.MAC("BAD"); got != nil {
+		t.Errorf("MAC(BAD) = %v, want nil", got)
+	}
+	if got := e.MAC("BAD", want); !reflect.DeepEqual(got, want) {
+		t.Errorf("MAC(BAD) = %v, want fallback %v", got, want)
+	}
+}
+
+func TestListAuto(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"COMMA":     "a, b,,c",
+		"SEMICOLON": "a;b ; c",
+		"SPACE":     "a  b\tc",
+		"NEWLINE":   "a b\nc\n",
+		"MIXED":     "a;b,c",
+	})
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"COMMA", []string{"a", "b", "c"}},
+		{"SEMICOLON", []string{"a", "b", "c"}},
+		{"SPACE", []string{"a", "b", "c"}},
+		{"NEWLINE", []string{"a b", "c"}},
+		{"MIXED", []string{"a;b", "c"}},
+		{"MISSING", []string{}},
+	}
+	for _, tt := range tests {
+		if got := e.ListAuto(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListAuto(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"MB":      "10MB/s",
+		"KIB":     "500KiB/s",
+		"MINUTE":  "60MB/m",
+		"BARE":    "1024",
+		"NO_UNIT": "2KB",
+		"BAD":     "fast/s",
+		"BAD_PER": "10MB/week",
+		"HUGE":    "9999999999TB/s",
+		"MAX":     "8388607TiB/s",
+		"OVER":    "8388608TiB/s",
+	})
+	tests := []struct {
+		key  string
+		want int64
+	}{
+		{"MB", 10e6},
+		{"KIB", 500 * 1024},
+		{"MINUTE", 1e6},
+		{"BARE", 1024},
+		{"NO_UNIT", 2000},
+		{"MAX", 8388607 << 40},
+		{"MISSING", 0},
+	}
+	for _, tt := range tests {
+		if got, err := e.Bandwidth(tt.key); err != nil || got != tt.want {
+			t.Errorf("Bandwidth(%s) = %d, %v, want %d", tt.key, got, err, tt.want)
+		}
+	}
+	for _, key := range []string{"BAD", "BAD_PER", "HUGE", "OVER"} {
+		if _, err := e.Bandwidth(key); err == nil {
+			t.Errorf("Bandwidth(%s) should fail", key)
+		}
+	}
+}
+
+func TestMust(t *testing.T) {
+	e := newTestEnviron(map[string]string{"NAME": "demo", "PORT": "80", "DEBUG": "yes", "TIMEOUT": "5", "BAD": "x"})
+	if e.MustString("NAME") != "demo" || e.MustInt("PORT") != 80 || !e.MustBool("DEBUG") || e.MustDuration("TIMEOUT") != 5*time.Second {
+		t.Error("Must* returned unexpected values for valid data")
+	}
+
+	mustPanic := func(want string, fn func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			err, _ := recover().(error)
+			if err == nil || err.Error() != want {
+				t.Errorf("panic = %v, want %s", err, want)
+			}
+		}()
+		fn()
+	}
+	mustPanic(`env: missing required variable "MISSING"`, func() { e.MustString("MISSING") })
+	mustPanic(`env: missing required variable "MISSING"`, func() { e.MustInt("MISSING") })
+	mustPanic(`env: invalid value "x" for "BAD"`, func() { e.MustInt("BAD") })
+	mustPanic(`env: invalid value "x" for "BAD"`, func() { e.MustBool("BAD") })
+	mustPanic(`env: invalid value "x" for "BAD"`, func() { e.MustDuration("BAD") })
+}
+
+func TestListSep(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"PATHS":    "/usr/bin: /bin:",
+		"HEADERS":  "a,b || c,d ||",
+		"SINGLE":   "a",
+		"CSV_LIST": "a, b",
+	})
+	tests := []struct {
+		key, sep string
+		want     []string
+	}{
+		{"PATHS", ":", []string{"/usr/bin", "/bin", ""}},
+		{"HEADERS", "||", []string{"a,b", "c,d", ""}},
+		{"SINGLE", ";", []string{"a"}},
+		{"CSV_LIST", ",", e.List("CSV_LIST")},
+		{"MISSING", ":", []string{}},
+	}
+	for _, tt := range tests {
+		if got := e.ListSep(tt.key, tt.sep); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListSep(%s, %q) = %q, want %q", tt.key, tt.sep, got, tt.want)
+		}
+	}
+	if got, want := e.ListSep("MISSING", ":", []string{"x"}), []string{"x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSep(MISSING) = %q, want fallback %q", got, want)
+	}
+}
+
+func TestBind(t *testing.T) {
+	e := newTestEnviron(map[string]string{"PORT": "8080", "NAME": "demo", "DEBUG": "true", "TIMEOUT": "5s", "BAD": "x"})
+	var (
+		port    int
+		name    string
+		debug   bool
+		timeout time.Duration
+	)
+	for key, target := range map[string]any{"PORT": &port, "NAME": &name, "DEBUG": &debug, "TIMEOUT": &timeout} {
+		if err := e.Bind(key, target); err != nil {
+			t.Errorf("Bind(%s) = %v", key, err)
+		}
+	}
+	if port != 8080 || name != "demo" || !debug || timeout != 5*time.Second {
+		t.Errorf("Bind() = %d %q %v %v", port, name, debug, timeout)
+	}
+
+	if err := e.Bind("MISSING", &port, 9090); err != nil || port != 9090 {
+		t.Errorf("Bind(MISSING, 9090) = %d, %v, want fallback 9090", port, err)
+	}
+	if err := e.Bind("MISSING", &timeout, time.Minute); err != nil || timeout != time.Minute {
+		t.Errorf("Bind(MISSING, 1m) = %v, %v, want fallback 1m", timeout, err)
+	}
+	if err := e.Bind("MISSING", &name); err != nil || name != "demo" {
+		t.Errorf("Bind(MISSING) = %q, %v, want the target unchanged", name, err)
+	}
+	if err := e.Bind("MISSING", &port, "9090"); err == nil {
+		t.Error("Bind() with a fallback of the wrong type should fail")
+	}
+	if err := e.Bind("BAD", &port); err == nil {
+		t.Error("Bind(BAD) should fail")
+	}
+	if err := e.Bind("PORT", port); err == nil {
+		t.Error("Bind() with a non-pointer target should fail")
+	}
+	if err := e.Bind("PORT", (*int)(nil)); err == nil {
+		t.Error("Bind() with a nil pointer should fail")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"FLAGS":   `{"beta":true,"limit":3}`,
+		"LABELS":  `{"env":"prod"}`,
+		"HOSTS":   `["a","b"]`,
+		"INVALID": `{"beta":`,
+	})
+	var flags struct {
+		Beta  bool `json:"beta"`
+		Limit int  `json:"limit"`
+	}
+	if err := e.JSON("FLAGS", &flags); err != nil || !flags.Beta || flags.Limit != 3 {
+		t.Errorf("JSON(FLAGS) = %+v, %v", flags, err)
+	}
+	var labels map[string]string
+	if err := e.JSON("LABELS", &labels); err != nil || !reflect.DeepEqual(labels, map[string]string{"env": "prod"}) {
+		t.Errorf("JSON(LABELS) = %v, %v", labels, err)
+	}
+	var hosts []string
+	if err := e.JSON("HOSTS", &hosts); err != nil || !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Errorf("JSON(HOSTS) = %v, %v", hosts, err)
+	}
+	if err := e.JSON("MISSING", &hosts); err == nil || err.Error() != `env: missing required variable "MISSING"` {
+		t.Errorf("JSON(MISSING) = %v, want a missing variable error", err)
+	}
+	if err := e.JSON("INVALID", &flags); err == nil {
+		t.Error("JSON(INVALID) should fail")
+	}
+}
+
+func TestIntArray3(t *testing.T) {
+	e := newTestEnviron(map[string]string{"COLOR": "255, 128,0", "SHORT": "1,2", "LONG": "1,2,3,4", "BAD": "1,x,3"})
+	if got, err := e.IntArray3("COLOR"); err != nil || got != [3]int{255, 128, 0} {
+		t.Errorf("IntArray3(COLOR) = %v, %v, want [255 128 0]", got, err)
+	}
+	for _, key := range []string{"SHORT", "LONG", "BAD", "MISSING"} {
+		if _, err := e.IntArray3(key); err == nil {
+			t.Errorf("IntArray3(%s) should fail", key)
+		}
+	}
+}
+
+func TestBase64(t *testing.T) {
+	e := newTestEnviron(map[string]string{"PADDED": "aGVsbG8=", "UNPADDED": "aGVsbG8", "INVALID": "not base64!"})
+	for _, key := range []string{"PADDED", "UNPADDED"} {
+		if got, err := e.Base64(key); err != nil || string(got) != "hello" {
+			t.Errorf("Base64(%s) = %q, %v, want hello", key, got, err)
+		}
+	}
+	if _, err := e.Base64("INVALID", []byte("x")); err == nil {
+		t.Error("Base64(INVALID) should fail")
+	}
+	if got, err := e.Base64("MISSING", []byte("x")); err != nil || string(got) != "x" {
+		t.Errorf("Base64(MISSING) = %q, %v, want fallback", got, err)
+	}
+	if got, err := e.Base64("MISSING"); err != nil || got != nil {
+		t.Errorf("Base64(MISSING) = %q, %v, want nil", got, err)
+	}
+}
+
+func TestTime(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"START": "2024-01-02T15:04:05Z",
+		"DATE":  "2024-01-02",
+		"BAD":   "tomorrow",
+	})
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := e.Time("START"), time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Time(START) = %v, want %v", got, want)
+	}
+	if got, want := e.TimeLayout("DATE", time.DateOnly), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("TimeLayout(DATE) = %v, want %v", got, want)
+	}
+	if got := e.Time("DATE", fallback); !got.Equal(fallback) {
+		t.Errorf("Time(DATE) = %v, a date-only value is not RFC3339", got)
+	}
+	if got := e.Time("BAD", fallback); !got.Equal(fallback) {
+		t.Errorf("Time(BAD) = %v, want fallback", got)
+	}
+	if got := e.Time("MISSING"); !got.IsZero() {
+		t.Errorf("Time(MISSING) = %v, want zero", got)
+	}
+}
+
+func TestPtr(t *testing.T) {
+	e := newTestEnviron(map[string]string{"NAME": "demo", "EMPTY": "", "PORT": "0", "DEBUG": "false", "BAD": "x"})
+	if got := e.StringPtr("NAME"); got == nil || *got != "demo" {
+		t.Errorf("StringPtr(NAME) = %v, want demo", got)
+	}
+	if got := e.StringPtr("EMPTY"); got == nil || *got != "" {
+		t.Errorf("StringPtr(EMPTY) = %v, want a pointer to an empty string", got)
+	}
+	if got := e.IntPtr("PORT"); got == nil || *got != 0 {
+		t.Errorf("IntPtr(PORT) = %v, want 0", got)
+	}
+	if got := e.BoolPtr("DEBUG"); got == nil || *got {
+		t.Errorf("BoolPtr(DEBUG) = %v, want false", got)
+	}
+	if e.StringPtr("MISSING") != nil || e.IntPtr("MISSING") != nil || e.BoolPtr("MISSING") != nil {
+		t.Error("missing keys should return nil")
+	}
+	if e.IntPtr("BAD") != nil || e.BoolPtr("BAD") != nil {
+		t.Error("invalid values should return nil")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	e := newTestEnviron(map[string]string{"PORT": "abc", "DEBUG": "maybe", "TIMEOUT": "soon", "RATIO": "half"})
+	if n, err := e.IntE("PORT", 80); err == nil || n != 80 {
+		t.Errorf("IntE(PORT) = %d, %v, want fallback and an error", n, err)
+	}
+	if _, err := e.BoolE("DEBUG"); err == nil {
+		t.Error("BoolE(DEBUG) should fail")
+	}
+	if _, err := e.DurationE("TIMEOUT"); err == nil {
+		t.Error("DurationE(TIMEOUT) should fail")
+	}
+	if _, err := e.Float64E("RATIO"); err == nil {
+		t.Error("Float64E(RATIO) should fail")
+	}
+	if n, err := e.IntE("MISSING", 80); err != nil || n != 80 {
+		t.Errorf("IntE(MISSING) = %d, %v, want fallback without an error", n, err)
+	}
+	if _, err := e.DurationE("MISSING"); err != nil {
+		t.Errorf("DurationE(MISSING) = %v, want nil", err)
+	}
+	// 不带 E 的方法保持原有的行为
+	if got := e.Int("PORT", 80); got != 80 {
+		t.Errorf("Int(PORT) = %d, want fallback 80", got)
+	}
+}
+
+func TestDurationSum(t *testing.T) {
+	e := newTestEnviron(map[string]string{"BUDGET": "30s, 500ms,1m", "SINGLE": "2", "BAD": "30s,soon"})
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"BUDGET", 90*time.Second + 500*time.Millisecond},
+		{"SINGLE", 2 * time.Second},
+		{"BAD", 0},
+		{"MISSING", 0},
+	}
+	for _, tt := range tests {
+		if got := e.DurationSum(tt.key); got != tt.want {
+			t.Errorf("DurationSum(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCron(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"FIVE":          " */5  * * * * ",
+		"SIX":           "0 30 2 * * mon-fri",
+		"NAMES":         "0 0 1 jan,Jul ?",
+		"SUNDAY":        "0 0 * * 7",
+		"FEW":           "* * *",
+		"MANY":          "* * * * * * *",
+		"UNKNOWN_NAME":  "* * * * FOO",
+		"MONTH_AS_DAY":  "* * * * JAN",
+		"REVERSED":      "* * 5-1 * *",
+		"REVERSED_NAME": "* * * * FRI-MON",
+		"OUT_OF_RANGE":  "60 * * * *",
+		"BAD_STEP":      "*/0 * * * *",
+	})
+	valid := map[string]string{
+		"FIVE":   "*/5 * * * *",
+		"SIX":    "0 30 2 * * mon-fri",
+		"NAMES":  "0 0 1 jan,Jul ?",
+		"SUNDAY": "0 0 * * 7",
+	}
+	for key, want := range valid {
+		if got, err := e.Cron(key); err != nil || got != want {
+			t.Errorf("Cron(%s) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+	for _, key := range []string{"FEW", "MANY", "UNKNOWN_NAME", "MONTH_AS_DAY", "REVERSED", "REVERSED_NAME", "OUT_OF_RANGE", "BAD_STEP", "MISSING"} {
+		if _, err := e.Cron(key); err == nil {
+			t.Errorf("Cron(%s) should fail", key)
+		}
+	}
+}
+
+func TestFillAll(t *testing.T) {
+	type config struct {
+		Port    int           `env:"APP_PORT"`
+		Name    string        `env:"APP_NAME"`
+		Timeout time.Duration `env:"APP_TIMEOUT"`
+		Debug   bool          `env:"APP_DEBUG"`
+	}
+	e := newTestEnviron(map[string]string{
+		"APP_PORT":    "http",
+		"APP_NAME":    "demo",
+		"APP_TIMEOUT": "soon",
+		"APP_DEBUG":   "true",
+	})
+
+	var c config
+	err := e.FillAll(&c)
+	if err == nil {
+		t.Fatal("FillAll() should fail")
+	}
+	for _, s := range []string{"Port", "APP_PORT", "Timeout", "APP_TIMEOUT"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("FillAll() error %q does not mention %s", err, s)
+		}
+	}
+	// 其余字段仍会被填充
+	if c.Name != "demo" || !c.Debug {
+		t.Errorf("FillAll() = %+v, valid fields should still be filled", c)
+	}
+
+	// Fill 在第一个错误处停止
+	var first config
+	if err := e.Fill(&first); err == nil || strings.Contains(err.Error(), "APP_TIMEOUT") {
+		t.Errorf("Fill() = %v, want only the first error", err)
+	}
+	if err := e.FillAll(config{}); err == nil {
+		t.Error("FillAll() with a non-pointer should fail")
+	}
+}
@@ -0,0 +1,39 @@
+package env
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	// 判断键名是否敏感的匹配规则，键名包含其中任意一项（忽略大小写）即视为敏感
+	sensitivePatterns = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE_KEY", "API_KEY"}
+	sensitiveMu       sync.RWMutex
+)
+
+// IsSensitiveKey 判断键名是否可能对应敏感数据（如密码、密钥等），
+// 可用于在日志中对相应的值进行脱敏处理
+func IsSensitiveKey(key string) bool {
+	key = strings.ToUpper(key)
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+	for _, pattern := range sensitivePatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSensitivePatterns 替换判断敏感键名的匹配规则
+func SetSensitivePatterns(patterns []string) {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = strings.ToUpper(strings.TrimSpace(pattern)); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	sensitiveMu.Lock()
+	defer sensitiveMu.Unlock()
+	sensitivePatterns = normalized
+}
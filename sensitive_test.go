@@ -0,0 +1,36 @@
+package env
+
+import "testing"
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"DB_PASSWORD", true},
+		{"client_secret", true},
+		{"GITHUB_TOKEN", true},
+		{"PORT", false},
+		{"CERT_PIN", false},
+	}
+	for _, tt := range tests {
+		if got := IsSensitiveKey(tt.key); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSetSensitivePatterns(t *testing.T) {
+	sensitiveMu.RLock()
+	prev := sensitivePatterns
+	sensitiveMu.RUnlock()
+	defer SetSensitivePatterns(prev)
+
+	SetSensitivePatterns([]string{" pin ", ""})
+	if !IsSensitiveKey("CERT_PIN") {
+		t.Error("custom pattern PIN was not applied")
+	}
+	if IsSensitiveKey("DB_PASSWORD") {
+		t.Error("SetSensitivePatterns should replace the default patterns")
+	}
+}
@@ -1,6 +1,9 @@
 package env
 
-import "strings"
+import (
+	"os"
+	"strings"
+)
 
 var _ Signer = &signer{}
 
@@ -20,10 +23,68 @@ func newSigner(prefix, category string, environ *environ) Signer {
 	s.inner.lookup = s.lookup
 	s.inner.exists = s.exists
 	s.inner.iter = s.iter
+	s.inner.qualify = s.qualify
+	s.inner.root = s.environ.lookup
+	s.inner.save = s.save
+	s.inner.candidates = s.candidates
+	s.inner.withEncoding = s.withEncoding
 	return s
 }
 
+// 返回一个相同作用域的查询器，其 Bytes 方法会按照 enc 解码数据
+func (s *signer) withEncoding(enc Encoding) Signer {
+	c := newSigner(s.prefix, s.category, s.environ).(*signer)
+	c.encoding = enc
+	return c
+}
+
+// save 将短键名的数据以 prefix_category_key 的完整键名写入根作用域
+func (s *signer) save(data map[string]string) {
+	result := make(map[string]string, len(data))
+	for key, value := range data {
+		result[s.join(s.category, key)] = value
+	}
+	s.environ.Save(result)
+}
+
 func (s *signer) lookup(key string) (string, bool) {
+	return s.finish(s.lookupRaw(key))
+}
+
+// candidates 依次返回类目键与缺省键的值，不存在的键会被忽略
+func (s *signer) candidates(key string) []string {
+	var values []string
+	if s.category != "" {
+		if value, ok := s.finish(s.lookup2(s.category, key)); ok {
+			values = append(values, value)
+		}
+	}
+	if value, ok := s.finish(s.lookup2("", key)); ok {
+		values = append(values, value)
+	}
+	return values
+}
+
+// finish 对查找到的原始值进行变量展开与协议解析
+func (s *signer) finish(value string, exists bool) (string, bool) {
+	if exists && s.environ.expanding() {
+		// 引用优先在当前作用域内查找，找不到时再查找根作用域
+		value = os.Expand(value, func(name string) string {
+			if v, ok := s.lookupRaw(name); ok {
+				return v
+			}
+			v, _ := s.environ.lookupRaw(name)
+			return v
+		})
+		exists = len(value) > 0
+	}
+	if exists {
+		value, exists = s.environ.resolve(value)
+	}
+	return value, exists
+}
+
+func (s *signer) lookupRaw(key string) (string, bool) {
 	// 相当于使用 prefix 作为分组，category 表示不同类目，
 	// 最终形成 prefix_category_key 这样的数据键名称
 	value, exists := s.lookup2(s.category, key)
@@ -36,13 +97,26 @@ func (s *signer) lookup(key string) (string, bool) {
 }
 
 func (s *signer) lookup2(category, key string) (string, bool) {
+	return s.environ.lookupRaw(s.join(category, key))
+}
+
+// join 拼接出 prefix_category_key 形式的完整键名
+func (s *signer) join(category, key string) string {
 	if category != "" {
 		key = category + "_" + key
 	}
 	if s.prefix != "" {
 		key = s.prefix + "_" + key
 	}
-	return s.environ.Lookup(key)
+	return key
+}
+
+// qualify 返回短键名在根作用域中实际对应的完整键名
+func (s *signer) qualify(key string) string {
+	if s.category != "" && s.exists2(s.category, key) {
+		return s.join(s.category, key)
+	}
+	return s.join("", key)
 }
 
 func (s *signer) exists(key string) bool {
@@ -58,48 +132,46 @@ func (s *signer) exists(key string) bool {
 }
 
 func (s *signer) exists2(category, key string) bool {
-	if category != "" {
-		key = category + "_" + key
-	}
-	if s.prefix != "" {
-		key = s.prefix + "_" + key
-	}
-	return s.environ.Exists(key)
+	return s.environ.Exists(s.join(category, key))
 }
 
+// iter 在创建时获取根作用域的快照并计算当前作用域的视图：先返回类目键（prefix_category_key），
+// 再返回未被类目键覆盖的缺省键（prefix_key），键名均已去除前缀，遍历过程中的写入不会影响本次遍历
 func (s *signer) iter() func() (key string, value string, ok bool) {
-	next := s.environ.inner.iter()
-	prefix := s.prefix
-	if prefix != "" {
-		prefix += "_"
+	pairs := s.environ.Pairs()
+	scoped := s.join(s.category, "")
+	fallback := s.join("", "")
+	var result []Pair
+	seen := make(map[string]bool)
+	consumed := make(map[string]bool)
+	for _, pair := range pairs {
+		if !strings.HasPrefix(pair.Key, scoped) {
+			continue
+		}
+		if name := strings.TrimPrefix(pair.Key, scoped); name != "" {
+			result = append(result, Pair{Key: name, Value: pair.Value})
+			seen[name] = true
+			consumed[pair.Key] = true
+		}
 	}
 	if s.category != "" {
-		prefix += s.category + "_"
+		for _, pair := range pairs {
+			if consumed[pair.Key] || !strings.HasPrefix(pair.Key, fallback) {
+				continue
+			}
+			if name := strings.TrimPrefix(pair.Key, fallback); name != "" && !seen[name] {
+				result = append(result, Pair{Key: name, Value: pair.Value})
+				seen[name] = true
+			}
+		}
 	}
-	var keys, values []string
 	var index int
 	return func() (key string, value string, ok bool) {
-		if next == nil {
-			if index >= len(keys) {
-				return "", "", false
-			}
-			defer func() {
-				index++
-			}()
-			return keys[index], values[index], true
-		}
-		for {
-			k, v, b := next()
-			if !b {
-				return "", "", false
-			}
-			if strings.HasPrefix(k, prefix) {
-				return strings.TrimPrefix(k, prefix), v, true
-			}
-			if s.prefix != "" && strings.HasPrefix(k, s.prefix) {
-				keys = append(keys, strings.TrimPrefix(k, s.prefix))
-				values = append(values, value)
-			}
+		if index >= len(result) {
+			return "", "", false
 		}
+		pair := result[index]
+		index++
+		return pair.Key, pair.Value, true
 	}
 }
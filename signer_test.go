@@ -0,0 +1,257 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignerExportEnviron(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"CACHE_DRIVER":        "redis",
+		"CACHE_DATABASE":      "1",
+		"CACHE_BOOK_DATABASE": "10",
+		"OTHER":               "x",
+	})
+	got := e.Signed("CACHE", "BOOK").ExportEnviron()
+	sort.Strings(got)
+	want := []string{"DATABASE=10", "DRIVER=redis"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportEnviron() = %q, want %q", got, want)
+	}
+}
+
+func TestSignerDuration(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"HTTP_TIMEOUT":       "30",
+		"HTTP_READ_TIMEOUT":  "5",
+		"HTTP_API_TIMEOUT":   "10",
+		"HTTP_API_KEEPALIVE": "1m",
+		"HTTP_KEEPALIVE":     "90s",
+	})
+	root := e.Signed("HTTP", "")
+	api := e.Signed("HTTP", "API")
+	tests := []struct {
+		name string
+		s    Signer
+		key  string
+		want time.Duration
+	}{
+		{"prefix only", root, "TIMEOUT", 30 * time.Second},
+		{"prefix only nested", root, "READ_TIMEOUT", 5 * time.Second},
+		{"category", api, "TIMEOUT", 10 * time.Second},
+		{"category suffixed", api, "KEEPALIVE", time.Minute},
+		{"fallback", api, "READ_TIMEOUT", 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Duration(tt.key); got != tt.want {
+			t.Errorf("%s: Duration(%q) = %v, want %v", tt.name, tt.key, got, tt.want)
+		}
+	}
+	if got, want := root.Duration("TIMEOUT"), e.Duration("HTTP_TIMEOUT"); got != want {
+		t.Errorf("signer Duration = %v, root Duration = %v", got, want)
+	}
+}
+
+func TestSignerExpand(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"SCOPE":            "root",
+		"HOST":             "example.com",
+		"CACHE_BOOK_SCOPE": "books",
+		"CACHE_BOOK_KEY":   "${SCOPE}:items",
+		"CACHE_BOOK_URL":   "https://${HOST}/${SCOPE}",
+		"CACHE_PREFIX":     "${SCOPE}:cache",
+		"CACHE_MISSING":    "${NOPE}",
+	})
+	s := e.Signed("CACHE", "BOOK")
+	if got := s.String("KEY"); got != "${SCOPE}:items" {
+		t.Errorf("without expansion KEY = %q", got)
+	}
+
+	e.SetExpand(true)
+	tests := []struct {
+		key, want string
+	}{
+		{"KEY", "books:items"},
+		{"URL", "https://example.com/books"},
+		{"PREFIX", "books:cache"},
+	}
+	for _, tt := range tests {
+		if got := s.String(tt.key); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := e.String("CACHE_BOOK_KEY"); got != "root:items" {
+		t.Errorf("root String(CACHE_BOOK_KEY) = %q, want root:items", got)
+	}
+	if _, ok := s.Lookup("MISSING"); ok {
+		t.Error("a value expanding to empty should not be found")
+	}
+}
+
+func TestSignerWithEncoding(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"SECRETS_KEY":  "aGVsbG8=",
+		"SECRETS_SALT": "c2FsdA",
+		"SECRETS_HEX":  "not base64!",
+		"HEX_KEY":      "68656c6c6f",
+	})
+	s := e.Signed("SECRETS", "").WithEncoding(EncodingBase64)
+	if got := string(s.Bytes("KEY")); got != "hello" {
+		t.Errorf("Bytes(KEY) = %q, want hello", got)
+	}
+	if got := string(s.Bytes("SALT")); got != "salt" {
+		t.Errorf("Bytes(SALT) = %q, want salt", got)
+	}
+	if got := string(s.Bytes("HEX", []byte("fallback"))); got != "fallback" {
+		t.Errorf("Bytes(HEX) = %q, want fallback", got)
+	}
+	if got := string(e.Bytes("SECRETS_KEY")); got != "aGVsbG8=" {
+		t.Errorf("root Bytes(SECRETS_KEY) = %q, want the raw value", got)
+	}
+	if got := string(e.Signed("SECRETS", "").Bytes("KEY")); got != "aGVsbG8=" {
+		t.Errorf("plain signer Bytes(KEY) = %q, want the raw value", got)
+	}
+	if got := string(e.Signed("HEX", "").WithEncoding(EncodingHex).Bytes("KEY")); got != "hello" {
+		t.Errorf("hex Bytes(KEY) = %q, want hello", got)
+	}
+	if got := string(e.WithEncoding(EncodingBase64).Bytes("SECRETS_KEY")); got != "hello" {
+		t.Errorf("root WithEncoding Bytes(SECRETS_KEY) = %q, want hello", got)
+	}
+}
+
+func TestSignerWhereQualified(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"CACHE_BOOK_X": "1",
+		"CACHE_Y":      "2",
+		"OTHER":        "3",
+	})
+	seen := map[string]string{}
+	got := e.Signed("CACHE", "BOOK").WhereQualified(func(qualifiedKey, shortKey, value string) bool {
+		seen[shortKey] = qualifiedKey
+		return qualifiedKey == "CACHE_BOOK_X"
+	})
+	if want := map[string]string{"X": "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WhereQualified() = %v, want %v", got, want)
+	}
+	if want := map[string]string{"X": "CACHE_BOOK_X", "Y": "CACHE_Y"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("filter received %v, want %v", seen, want)
+	}
+}
+
+func TestSignerIntPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want int
+	}{
+		{"category", map[string]string{"CACHE_BOOK_DATABASE": "10", "CACHE_DATABASE": "1"}, 10},
+		{"prefix", map[string]string{"CACHE_DATABASE": "1"}, 1},
+		{"fallback", map[string]string{"OTHER_DATABASE": "1"}, 5},
+	}
+	for _, tt := range tests {
+		s := newTestEnviron(tt.data).Signed("CACHE", "BOOK")
+		if got := s.Int("DATABASE", 5); got != tt.want {
+			t.Errorf("%s: Int(DATABASE, 5) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSignerSave(t *testing.T) {
+	e := newTestEnviron(map[string]string{"CACHE_BOOK_DATABASE": "10"})
+	s := e.Signed("CACHE", "BOOK")
+	s.Save(map[string]string{"DATABASE": "11", "DRIVER": "redis"})
+	e.Signed("CACHE", "").Save(map[string]string{"TTL": "60"})
+
+	assertValues(t, e, map[string]string{
+		"CACHE_BOOK_DATABASE": "11",
+		"CACHE_BOOK_DRIVER":   "redis",
+		"CACHE_TTL":           "60",
+		"DATABASE":            "",
+		"DRIVER":              "",
+	})
+	if got := s.Int("TTL"); got != 60 {
+		t.Errorf("TTL = %d through the category scope, want 60", got)
+	}
+}
+
+func TestSignerListMerged(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want []string
+	}{
+		{"category", map[string]string{"CACHE_BOOK_HOSTS": "a,b"}, []string{"a", "b"}},
+		{"fallback", map[string]string{"CACHE_HOSTS": "c, d"}, []string{"c", "d"}},
+		{"both", map[string]string{"CACHE_BOOK_HOSTS": "a,b", "CACHE_HOSTS": "b,c,,a"}, []string{"a", "b", "c"}},
+		{"neither", map[string]string{}, []string{}},
+	}
+	for _, tt := range tests {
+		s := newTestEnviron(tt.data).Signed("CACHE", "BOOK")
+		if got := s.ListMerged("HOSTS"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ListMerged(HOSTS) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSignerLookupScopedAndRoot(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             map[string]string
+		scoped, root     string
+		scopedOK, rootOK bool
+	}{
+		{"both", map[string]string{"CACHE_BOOK_X": "scoped", "X": "root"}, "scoped", "root", true, true},
+		{"scoped", map[string]string{"CACHE_X": "prefix"}, "prefix", "", true, false},
+		{"root", map[string]string{"X": "root"}, "", "root", false, true},
+		{"neither", map[string]string{}, "", "", false, false},
+	}
+	for _, tt := range tests {
+		s := newTestEnviron(tt.data).Signed("CACHE", "BOOK")
+		scoped, scopedOK, root, rootOK := s.LookupScopedAndRoot("X")
+		if scoped != tt.scoped || scopedOK != tt.scopedOK || root != tt.root || rootOK != tt.rootOK {
+			t.Errorf("%s: LookupScopedAndRoot(X) = %q, %v, %q, %v, want %q, %v, %q, %v",
+				tt.name, scoped, scopedOK, root, rootOK, tt.scoped, tt.scopedOK, tt.root, tt.rootOK)
+		}
+	}
+}
+
+func TestSignerIterFallback(t *testing.T) {
+	e := newTestEnviron(map[string]string{
+		"CACHE_DRIVER":        "redis",
+		"CACHE_DATABASE":      "1",
+		"CACHE_BOOK_DATABASE": "10",
+	})
+	got := e.Signed("CACHE", "BOOK").Where(func(string, string) bool { return true })
+	want := map[string]string{"DATABASE": "10", "DRIVER": "redis"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Where() = %v, want %v", got, want)
+	}
+}
+
+func TestSignerIterConcurrentWrites(t *testing.T) {
+	e := newTestEnviron(map[string]string{"CACHE_DRIVER": "redis", "CACHE_BOOK_DATABASE": "10"})
+	s := e.Signed("CACHE", "BOOK")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			key := "CACHE_BOOK_K" + strconv.Itoa(i%10)
+			e.Set(key, strconv.Itoa(i))
+			if i%3 == 0 {
+				e.Unset(key)
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		m := s.Map("")
+		if m["DRIVER"] != "redis" || m["DATABASE"] != "10" {
+			t.Fatalf("Map() = %v, missing stable keys", m)
+		}
+		s.Where(func(name, _ string) bool { return strings.HasPrefix(name, "K") })
+	}
+	<-done
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"time"
+)
+
+// WatchInterval Watch 检查文件是否发生变化的间隔
+var WatchInterval = time.Second
+
+// loadedFile 通过 Load、LoadNoOverride 或 Reload 加载过的文件
+type loadedFile struct {
+	name     string
+	override bool
+}
+
+// track 记录加载过的文件，同一文件只记录一次并保持第一次加载的顺序，调用方不能持有锁
+func (e *environ) track(override bool, filenames ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+next:
+	for _, filename := range filenames {
+		for i, file := range e.files {
+			if file.name == filename {
+				e.files[i].override = override
+				continue next
+			}
+		}
+		e.files = append(e.files, loadedFile{name: filename, override: override})
+	}
+}
+
+// Watch 与 WatchChanges 相同，但 onReload 只接收错误，不关心具体变化了哪些键
+func (e *environ) Watch(ctx context.Context, onReload func(error)) error {
+	return e.WatchChanges(ctx, func(_ map[string]string, err error) {
+		if onReload != nil {
+			onReload(err)
+		}
+	})
+}
+
+// WatchChanges 定期检查加载过的文件（见 WatchInterval），文件内容发生变化时按照原来的顺序与覆盖规则
+// 重新加载，并一次性写入全部变化，读取方不会看到更新到一半的数据。
+//
+// 只有确实有键新增或值发生变化时才会调用 onChange，changed 只包含这些键及其新值，
+// 文件被重写但值没有变化时不会调用；重新加载失败时以相应的错误调用。从文件中删除的键不会被移除。
+// WatchChanges 不会阻塞，取消 ctx 即可停止检查，没有加载过任何文件时返回错误。
+func (e *environ) WatchChanges(ctx context.Context, onChange func(changed map[string]string, err error)) error {
+	e.mu.RLock()
+	files := append([]loadedFile(nil), e.files...)
+	e.mu.RUnlock()
+	if len(files) == 0 {
+		return errors.New("env: no files to watch")
+	}
+	contents := readContents(files)
+	interval := WatchInterval
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := readContents(files)
+			if maps.Equal(contents, current) {
+				continue
+			}
+			contents = current
+			changed, err := e.reloadFiles(files)
+			if onChange != nil && (err != nil || len(changed) > 0) {
+				onChange(changed, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// readContents 读取文件的内容，无法读取的文件视为空
+func readContents(files []loadedFile) map[string]string {
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		data, _ := os.ReadFile(file.name)
+		contents[file.name] = string(data)
+	}
+	return contents
+}
+
+// reloadFiles 按顺序重新加载文件并一次性写入变化，返回新增或值发生变化的键值
+// （不包含被禁止读取的键），不存在的文件会被忽略
+func (e *environ) reloadFiles(files []loadedFile) (changed map[string]string, err error) {
+	pairs := e.Pairs()
+	existing := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		existing[pair.Key] = true
+	}
+	vars := e.interpolation(pairs)
+	data := make(map[string]string)
+	missing := make(map[string][]string)
+	for _, file := range files {
+		values, unresolved, err := readFile(file.name, vars)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for key, value := range values {
+			if _, ok := data[key]; !file.override && (ok || existing[key]) {
+				continue
+			}
+			data[key] = value
+			vars.values[key] = value
+			missing[key] = unresolved[key]
+		}
+	}
+
+	changed = make(map[string]string)
+	e.mu.Lock()
+	keys := make([]string, 0, len(data))
+	for key, value := range data {
+		if old, ok := e.values[key]; !ok || old != value {
+			e.set(key, value)
+			keys = append(keys, key)
+			if !e.blocked[key] {
+				changed[key] = value
+			}
+		}
+	}
+	e.mu.Unlock()
+	e.remember(data, missing)
+	e.notify(keys...)
+	return changed, nil
+}
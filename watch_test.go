@@ -0,0 +1,105 @@
+package env
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fastWatch 缩短 Watch 的检查间隔，测试结束后恢复
+func fastWatch(t *testing.T) {
+	t.Helper()
+	prev := WatchInterval
+	WatchInterval = 5 * time.Millisecond
+	t.Cleanup(func() { WatchInterval = prev })
+}
+
+func TestWatchChangesOnlyReportsChangedKeys(t *testing.T) {
+	fastWatch(t)
+	filename := writeFile(t, t.TempDir(), ".env", "A=1\nB=2\n")
+	e := newTestEnviron(nil)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := make(chan map[string]string, 10)
+	err := e.WatchChanges(ctx, func(changed map[string]string, err error) {
+		if err != nil {
+			t.Errorf("onChange error: %v", err)
+		}
+		calls <- changed
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 内容完全相同与只有注释、格式不同的重写都不应触发回调
+	writeFile(t, "", filename, "A=1\nB=2\n")
+	writeFile(t, "", filename, "# comment\nA=1\nB = \"2\"\n")
+	select {
+	case changed := <-calls:
+		t.Fatalf("unexpected callback for an identical rewrite: %v", changed)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	writeFile(t, "", filename, "A=1\nB=3\n")
+	select {
+	case changed := <-calls:
+		if want := map[string]string{"B": "3"}; !reflect.DeepEqual(changed, want) {
+			t.Errorf("changed = %v, want %v", changed, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no callback after a key changed")
+	}
+	if got := e.String("B"); got != "3" {
+		t.Errorf("B = %q after reload, want 3", got)
+	}
+}
+
+func TestWatchWithoutFiles(t *testing.T) {
+	e := newTestEnviron(map[string]string{"A": "1"})
+	if err := e.WatchChanges(context.Background(), nil); err == nil {
+		t.Error("WatchChanges should fail when no files were loaded")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	fastWatch(t)
+	filename := writeFile(t, t.TempDir(), ".env", "PORT=80\n")
+	e := newTestEnviron(nil)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 10)
+	if err := e.Watch(ctx, func(err error) { reloaded <- err }); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, "", filename, "PORT=8080\n")
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("onReload error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("file was not reloaded after it changed")
+	}
+	if got := e.Int("PORT"); got != 8080 {
+		t.Errorf("PORT = %d after reload, want 8080", got)
+	}
+
+	// 取消 ctx 之后不再重新加载
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	writeFile(t, "", filename, "PORT=9090\n")
+	time.Sleep(50 * time.Millisecond)
+	if got := e.Int("PORT"); got != 8080 {
+		t.Errorf("PORT = %d after cancel, want 8080", got)
+	}
+}